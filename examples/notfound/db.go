@@ -0,0 +1,34 @@
+// Code generated by sqlc. DO NOT EDIT.
+
+package notfound
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type DBTX interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+func New(db DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+type Queries struct {
+	db DBTX
+}
+
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db: tx,
+	}
+}
+
+// ErrNotFound is returned when a query expecting a single row finds none. It
+// wraps sql.ErrNoRows.
+var ErrNotFound = fmt.Errorf("not found: %w", sql.ErrNoRows)
@@ -0,0 +1,47 @@
+package notfound
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// noRowsDriver answers every query with zero rows
+type noRowsDriver struct{}
+
+func (noRowsDriver) Open(string) (driver.Conn, error) { return noRowsConn{}, nil }
+
+type noRowsConn struct{}
+
+func (noRowsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (noRowsConn) Close() error                        { return nil }
+func (noRowsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (noRowsConn) Query(string, []driver.Value) (driver.Rows, error) { return noRows{}, nil }
+
+type noRows struct{}
+
+func (noRows) Columns() []string         { return []string{"id", "name", "bio"} }
+func (noRows) Close() error              { return nil }
+func (noRows) Next([]driver.Value) error { return io.EOF }
+
+func init() {
+	sql.Register("norows", noRowsDriver{})
+}
+
+func TestGetFooNotFound(t *testing.T) {
+	db, err := sql.Open("norows", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = New(db).GetFoo(context.Background(), 1)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound; got %v", err)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNotFound to wrap sql.ErrNoRows; got %v", err)
+	}
+}
@@ -0,0 +1,2 @@
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
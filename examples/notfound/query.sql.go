@@ -0,0 +1,23 @@
+// Code generated by sqlc. DO NOT EDIT.
+// source: query.sql
+
+package notfound
+
+import (
+	"context"
+	"database/sql"
+)
+
+const getFoo = `-- name: GetFoo :one
+SELECT id, name, bio FROM foo WHERE id = $1
+`
+
+func (q *Queries) GetFoo(ctx context.Context, id int32) (Foo, error) {
+	row := q.db.QueryRowContext(ctx, getFoo, id)
+	var i Foo
+	err := row.Scan(&i.ID, &i.Name, &i.Bio)
+	if err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	return i, err
+}
@@ -0,0 +1,5 @@
+CREATE TABLE foo (
+    id   SERIAL PRIMARY KEY,
+    name text   NOT NULL,
+    bio  text
+);
@@ -55,56 +55,78 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	return &q, nil
 }
 
+// Close closes every prepared statement. The error wraps the first failure
+// and lists the rest.
 func (q *Queries) Close() error {
 	var err error
 	if q.createCityStmt != nil {
-		if cerr := q.createCityStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing createCityStmt: %w", cerr)
+		if cerr := q.createCityStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query CreateCity: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query CreateCity: %v", err, cerr)
 		}
 	}
 	if q.createVenueStmt != nil {
-		if cerr := q.createVenueStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing createVenueStmt: %w", cerr)
+		if cerr := q.createVenueStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query CreateVenue: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query CreateVenue: %v", err, cerr)
 		}
 	}
 	if q.deleteVenueStmt != nil {
-		if cerr := q.deleteVenueStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing deleteVenueStmt: %w", cerr)
+		if cerr := q.deleteVenueStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query DeleteVenue: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query DeleteVenue: %v", err, cerr)
 		}
 	}
 	if q.getCityStmt != nil {
-		if cerr := q.getCityStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getCityStmt: %w", cerr)
+		if cerr := q.getCityStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query GetCity: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query GetCity: %v", err, cerr)
 		}
 	}
 	if q.getVenueStmt != nil {
-		if cerr := q.getVenueStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getVenueStmt: %w", cerr)
+		if cerr := q.getVenueStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query GetVenue: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query GetVenue: %v", err, cerr)
 		}
 	}
 	if q.listCitiesStmt != nil {
-		if cerr := q.listCitiesStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing listCitiesStmt: %w", cerr)
+		if cerr := q.listCitiesStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query ListCities: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query ListCities: %v", err, cerr)
 		}
 	}
 	if q.listVenuesStmt != nil {
-		if cerr := q.listVenuesStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing listVenuesStmt: %w", cerr)
+		if cerr := q.listVenuesStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query ListVenues: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query ListVenues: %v", err, cerr)
 		}
 	}
 	if q.updateCityNameStmt != nil {
-		if cerr := q.updateCityNameStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing updateCityNameStmt: %w", cerr)
+		if cerr := q.updateCityNameStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query UpdateCityName: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query UpdateCityName: %v", err, cerr)
 		}
 	}
 	if q.updateVenueNameStmt != nil {
-		if cerr := q.updateVenueNameStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing updateVenueNameStmt: %w", cerr)
+		if cerr := q.updateVenueNameStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query UpdateVenueName: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query UpdateVenueName: %v", err, cerr)
 		}
 	}
 	if q.venueCountByCityStmt != nil {
-		if cerr := q.venueCountByCityStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing venueCountByCityStmt: %w", cerr)
+		if cerr := q.venueCountByCityStmt.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query VenueCountByCity: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query VenueCountByCity: %v", err, cerr)
 		}
 	}
 	return err
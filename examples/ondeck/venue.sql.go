@@ -73,6 +73,7 @@ WHERE slug = $1 AND city = $2
 `
 
 type GetVenueParams struct {
+	// This value appears in public URLs
 	Slug string `json:"slug"`
 	City string `json:"city"`
 }
@@ -144,6 +145,7 @@ RETURNING id
 `
 
 type UpdateVenueNameParams struct {
+	// This value appears in public URLs
 	Slug string `json:"slug"`
 	Name string `json:"name"`
 }
@@ -0,0 +1,24 @@
+package blob
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Blob is a bytea scanned into a copy of its bytes
+type Blob struct {
+	Bytes []byte
+}
+
+func (b *Blob) Scan(src interface{}) error {
+	raw, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected bytea %T", src)
+	}
+	b.Bytes = append([]byte(nil), raw...)
+	return nil
+}
+
+func (b Blob) Value() (driver.Value, error) {
+	return b.Bytes, nil
+}
@@ -0,0 +1,108 @@
+// Code generated by sqlc. DO NOT EDIT.
+// source: crud.sql
+
+package options
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kyleconroy/sqlc/examples/options/blob"
+	"github.com/kyleconroy/sqlc/examples/options/settings"
+	"github.com/lib/pq"
+)
+
+type createFooParams struct {
+	Name     string            `json:"name"`
+	Bio      NullString        `json:"bio"`
+	Count    NullInt32         `json:"count"`
+	Tags     []string          `json:"tags"`
+	Data     []byte            `json:"data"`
+	Thumb    blob.Blob         `json:"thumb"`
+	Settings settings.Settings `json:"settings"`
+	Mood     Mood              `json:"mood"`
+	Status   string            `json:"status"`
+	P        Pair              `json:"p"`
+}
+
+func (v createFooParams) String() string {
+	return fmt.Sprintf("createFooParams{Name:%v Bio:%v Count:%v Tags:%v Data:%v Thumb:%v Settings:%v Mood:%v Status:%v P:%v}", v.Name, v.Bio, v.Count, v.Tags, v.Data, v.Thumb, v.Settings, v.Mood, v.Status, v.P)
+}
+
+func (q *Queries) createFoo(ctx context.Context, arg createFooParams) (*Foo, error) {
+	if QueryHook != nil {
+		QueryHook(ctx, "createFoo", createFooQuery)
+	}
+	row := q.queryRow(ctx, q.createFooPrepared, createFooQuery,
+		arg.Name,
+		arg.Bio,
+		arg.Count,
+		pq.Array(arg.Tags),
+		arg.Data,
+		arg.Thumb,
+		jsonValue{arg.Settings},
+		arg.Mood,
+		arg.Status,
+		arg.P,
+	)
+	var i Foo
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Bio,
+		&i.Count,
+		pq.Array(&i.Tags),
+		&i.Data,
+		&i.Thumb,
+		jsonValue{&i.Settings},
+		&i.Mood,
+		&i.Status,
+		&i.P,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (q *Queries) listFoos(ctx context.Context) ([]Foo, error) {
+	if QueryHook != nil {
+		QueryHook(ctx, "listFoos", listFoosQuery)
+	}
+	rows, err := q.query(ctx, q.listFoosPrepared, listFoosQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Foo
+	for rows.Next() {
+		var i Foo
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Bio,
+			&i.Count,
+			pq.Array(&i.Tags),
+			&i.Data,
+			&i.Thumb,
+			jsonValue{&i.Settings},
+			&i.Mood,
+			&i.Status,
+			&i.P,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,358 @@
+// Code generated by sqlc. DO NOT EDIT.
+
+package options
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type DBTX interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+func New(db DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
+	q := Queries{db: db}
+	var err error
+	if q.createFooPrepared, err = db.PrepareContext(ctx, createFooQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query createFoo: %w", err)
+	}
+	if q.deleteFooPrepared, err = db.PrepareContext(ctx, deleteFooQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query deleteFoo: %w", err)
+	}
+	if q.getFooPrepared, err = db.PrepareContext(ctx, getFooQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query getFoo: %w", err)
+	}
+	if q.getFooNamePrepared, err = db.PrepareContext(ctx, getFooNameQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query getFooName: %w", err)
+	}
+	if q.listFooNamesPrepared, err = db.PrepareContext(ctx, listFooNamesQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query listFooNames: %w", err)
+	}
+	if q.listFoosPrepared, err = db.PrepareContext(ctx, listFoosQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query listFoos: %w", err)
+	}
+	if q.updateFooPrepared, err = db.PrepareContext(ctx, updateFooQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query updateFoo: %w", err)
+	}
+	if q.updateSettingsPrepared, err = db.PrepareContext(ctx, updateSettingsQuery); err != nil {
+		return nil, fmt.Errorf("error preparing query updateSettings: %w", err)
+	}
+	return &q, nil
+}
+
+// Close closes every prepared statement. The error wraps the first failure
+// and lists the rest.
+func (q *Queries) Close() error {
+	var err error
+	if q.createFooPrepared != nil {
+		if cerr := q.createFooPrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query createFoo: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query createFoo: %v", err, cerr)
+		}
+	}
+	if q.deleteFooPrepared != nil {
+		if cerr := q.deleteFooPrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query deleteFoo: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query deleteFoo: %v", err, cerr)
+		}
+	}
+	if q.getFooPrepared != nil {
+		if cerr := q.getFooPrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query getFoo: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query getFoo: %v", err, cerr)
+		}
+	}
+	if q.getFooNamePrepared != nil {
+		if cerr := q.getFooNamePrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query getFooName: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query getFooName: %v", err, cerr)
+		}
+	}
+	if q.listFooNamesPrepared != nil {
+		if cerr := q.listFooNamesPrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query listFooNames: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query listFooNames: %v", err, cerr)
+		}
+	}
+	if q.listFoosPrepared != nil {
+		if cerr := q.listFoosPrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query listFoos: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query listFoos: %v", err, cerr)
+		}
+	}
+	if q.updateFooPrepared != nil {
+		if cerr := q.updateFooPrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query updateFoo: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query updateFoo: %v", err, cerr)
+		}
+	}
+	if q.updateSettingsPrepared != nil {
+		if cerr := q.updateSettingsPrepared.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query updateSettings: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query updateSettings: %v", err, cerr)
+		}
+	}
+	return err
+}
+
+// createFooStmt returns the prepared statement for createFoo, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) createFooStmt() *sql.Stmt {
+	return q.createFooPrepared
+}
+
+// deleteFooStmt returns the prepared statement for deleteFoo, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) deleteFooStmt() *sql.Stmt {
+	return q.deleteFooPrepared
+}
+
+// getFooStmt returns the prepared statement for getFoo, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) getFooStmt() *sql.Stmt {
+	return q.getFooPrepared
+}
+
+// getFooNameStmt returns the prepared statement for getFooName, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) getFooNameStmt() *sql.Stmt {
+	return q.getFooNamePrepared
+}
+
+// listFooNamesStmt returns the prepared statement for listFooNames, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) listFooNamesStmt() *sql.Stmt {
+	return q.listFooNamesPrepared
+}
+
+// listFoosStmt returns the prepared statement for listFoos, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) listFoosStmt() *sql.Stmt {
+	return q.listFoosPrepared
+}
+
+// updateFooStmt returns the prepared statement for updateFoo, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) updateFooStmt() *sql.Stmt {
+	return q.updateFooPrepared
+}
+
+// updateSettingsStmt returns the prepared statement for updateSettings, or nil
+// if the queries were not created with Prepare.
+func (q *Queries) updateSettingsStmt() *sql.Stmt {
+	return q.updateSettingsPrepared
+}
+
+func (q *Queries) exec(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (sql.Result, error) {
+	switch {
+	case stmt != nil && q.tx != nil:
+		return q.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+	case stmt != nil:
+		return stmt.ExecContext(ctx, args...)
+	default:
+		return q.db.ExecContext(ctx, query, args...)
+	}
+}
+
+func (q *Queries) query(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error) {
+	switch {
+	case stmt != nil && q.tx != nil:
+		return q.tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
+	case stmt != nil:
+		return stmt.QueryContext(ctx, args...)
+	default:
+		return q.db.QueryContext(ctx, query, args...)
+	}
+}
+
+func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) *sql.Row {
+	switch {
+	case stmt != nil && q.tx != nil:
+		return q.tx.StmtContext(ctx, stmt).QueryRowContext(ctx, args...)
+	case stmt != nil:
+		return stmt.QueryRowContext(ctx, args...)
+	default:
+		return q.db.QueryRowContext(ctx, query, args...)
+	}
+}
+
+type Queries struct {
+	db                     DBTX
+	tx                     *sql.Tx
+	createFooPrepared      *sql.Stmt
+	deleteFooPrepared      *sql.Stmt
+	getFooPrepared         *sql.Stmt
+	getFooNamePrepared     *sql.Stmt
+	listFooNamesPrepared   *sql.Stmt
+	listFoosPrepared       *sql.Stmt
+	updateFooPrepared      *sql.Stmt
+	updateSettingsPrepared *sql.Stmt
+}
+
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db:                     tx,
+		tx:                     tx,
+		createFooPrepared:      q.createFooPrepared,
+		deleteFooPrepared:      q.deleteFooPrepared,
+		getFooPrepared:         q.getFooPrepared,
+		getFooNamePrepared:     q.getFooNamePrepared,
+		listFooNamesPrepared:   q.listFooNamesPrepared,
+		listFoosPrepared:       q.listFoosPrepared,
+		updateFooPrepared:      q.updateFooPrepared,
+		updateSettingsPrepared: q.updateSettingsPrepared,
+	}
+}
+
+// Store wraps a database handle, running queries directly or, with ExecTx,
+// inside a transaction.
+type Store struct {
+	*Queries
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{Queries: New(db), db: db}
+}
+
+// ExecTx calls fn with Queries bound to a new transaction, which is committed
+// if fn returns nil and rolled back otherwise.
+func (s *Store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(s.WithTx(tx)); err != nil {
+		// The error from fn says more than any error from rolling back
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// QueryHook, when set, is called with the name and SQL of each query before
+// it runs, e.g. for logging or tracing.
+var QueryHook func(ctx context.Context, name, query string)
+
+var (
+	// ErrUniqueViolation matches a *ConstraintError for a unique violation.
+	ErrUniqueViolation = errors.New("unique violation")
+
+	// ErrForeignKeyViolation matches a *ConstraintError for a foreign key violation.
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+)
+
+// ConstraintError is a constraint violation reported by the database. It
+// unwraps to the driver's error.
+type ConstraintError struct {
+	Kind       error
+	Constraint string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// ClassifyError turns unique and foreign key violations, identified by their
+// SQLSTATE code, into a *ConstraintError. Other errors are returned as is.
+func ClassifyError(err error) error {
+	var code, constraint string
+	var pqErr *pq.Error
+	var pgErr interface{ SQLState() string }
+	switch {
+	case errors.As(err, &pqErr):
+		code, constraint = string(pqErr.Code), pqErr.Constraint
+	case errors.As(err, &pgErr):
+		code = pgErr.SQLState()
+	default:
+		return err
+	}
+	switch code {
+	case "23505":
+		return &ConstraintError{Kind: ErrUniqueViolation, Constraint: constraint, Err: err}
+	case "23503":
+		return &ConstraintError{Kind: ErrForeignKeyViolation, Constraint: constraint, Err: err}
+	}
+	return err
+}
+
+// jsonValue scans a column with json.Unmarshal and passes a query argument
+// with json.Marshal
+type jsonValue struct {
+	v interface{}
+}
+
+func (j jsonValue) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, j.v)
+	case string:
+		return json.Unmarshal([]byte(src), j.v)
+	}
+	return fmt.Errorf("jsonValue: cannot scan %T", src)
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	return json.Marshal(j.v)
+}
+
+const ping = `SELECT 1`
+
+// Ping runs a trivial query to check that the database is reachable.
+func (q *Queries) Ping(ctx context.Context) error {
+	var one int
+	return q.db.QueryRowContext(ctx, ping).Scan(&one)
+}
+
+// Exec runs an arbitrary statement on the same handle as the generated queries.
+func (q *Queries) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return q.db.ExecContext(ctx, query, args...)
+}
+
+type Querier interface {
+	Ping(ctx context.Context) error
+	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	createFoo(ctx context.Context, arg createFooParams) (*Foo, error)
+	deleteFoo(ctx context.Context, id int32) error
+	getFoo(ctx context.Context, id int32) (*Foo, error)
+	getFooName(ctx context.Context, id int32) (string, error)
+	listFooNames(ctx context.Context, arg listFooNamesParams) ([]listFooNamesRow, error)
+	listFoos(ctx context.Context) ([]Foo, error)
+	updateFoo(ctx context.Context, arg updateFooParams) (int64, error)
+	updateSettings(ctx context.Context, arg updateSettingsParams) error
+}
+
+var _ Querier = (*Queries)(nil)
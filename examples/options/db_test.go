@@ -0,0 +1,324 @@
+package options
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kyleconroy/sqlc/examples/options/blob"
+	"github.com/kyleconroy/sqlc/examples/options/settings"
+	"github.com/lib/pq"
+)
+
+// fakeDriver answers every query with zero rows
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (fakeConn) Query(string, []driver.Value) (driver.Rows, error) { return noRows{}, nil }
+
+var errStmtClose = errors.New("close failed")
+
+// fakeStmt fails to close
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error  { return errStmtClose }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("not supported") }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return noRows{}, nil }
+
+type noRows struct{}
+
+func (noRows) Columns() []string         { return []string{"id"} }
+func (noRows) Close() error              { return nil }
+func (noRows) Next([]driver.Value) error { return io.EOF }
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+func TestGetFooNoRows(t *testing.T) {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	foo, err := New(db).getFoo(context.Background(), 1)
+	if foo != nil || err != nil {
+		t.Errorf("getFoo returned %v, %v", foo, err)
+	}
+}
+
+func TestStmtAccessor(t *testing.T) {
+	if New(nil).getFooStmt() != nil {
+		t.Errorf("statement prepared without Prepare")
+	}
+}
+
+func TestCloseReportsEveryStatement(t *testing.T) {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// Statements prepared on a connection report their driver's close error
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	q, err := Prepare(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = q.Close()
+	if !errors.Is(err, errStmtClose) {
+		t.Fatalf("expected the close error to be wrapped; got %v", err)
+	}
+	for _, name := range []string{"createFoo", "deleteFoo", "getFoo"} {
+		if !strings.Contains(err.Error(), "error closing query "+name+":") {
+			t.Errorf("close error doesn't name %s: %v", name, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	row := listFooNamesRow{ID: 2, Name: "bob"}
+	if s := row.String(); s != "listFooNamesRow{ID:2 Name:bob}" {
+		t.Fatalf("unexpected string %q", s)
+	}
+	arg := updateFooParams{ID: 1, Name: "alice"}
+	if s := arg.String(); s != "updateFooParams{ID:1 Name:alice}" {
+		t.Fatalf("unexpected string %q", s)
+	}
+}
+
+func TestDeepCopy(t *testing.T) {
+	v := Foo{ID: 1, Tags: []string{"a"}, Data: []byte("b")}
+	c := v.DeepCopy()
+	c.Tags[0] = "x"
+	c.Data[0] = 'x'
+	if v.Tags[0] != "a" || v.Data[0] != 'b' {
+		t.Errorf("DeepCopy shares slices with the original: %v", v)
+	}
+	if c.ID != 1 || len(c.Tags) != 1 {
+		t.Errorf("DeepCopy returned %v", c)
+	}
+	if empty := (Foo{}).DeepCopy(); empty.Tags != nil {
+		t.Errorf("DeepCopy of a nil slice is %v", empty.Tags)
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	var s settings.Settings
+	if err := (jsonValue{&s}).Scan([]byte(`{"theme":"dark"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Theme != "dark" {
+		t.Fatalf("unexpected theme %q", s.Theme)
+	}
+	v, err := jsonValue{s}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(v.([]byte)) != `{"theme":"dark"}` {
+		t.Fatalf("unexpected value %s", v)
+	}
+}
+
+var (
+	_ sql.Scanner   = &blob.Blob{}
+	_ driver.Valuer = blob.Blob{}
+)
+
+func TestBlobFields(t *testing.T) {
+	foo := Foo{Data: []byte("data"), Thumb: blob.Blob{Bytes: []byte("thumb")}}
+	arg := createFooParams{Data: foo.Data, Thumb: foo.Thumb}
+	if string(arg.Thumb.Bytes) != "thumb" {
+		t.Fatalf("unexpected thumb %q", arg.Thumb.Bytes)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	err := ClassifyError(fmt.Errorf("insert: %w", &pq.Error{Code: "23505", Constraint: "foo_pkey"}))
+	if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("expected ErrUniqueViolation; got %v", err)
+	}
+	var cerr *ConstraintError
+	if !errors.As(err, &cerr) || cerr.Constraint != "foo_pkey" {
+		t.Fatalf("expected a ConstraintError for foo_pkey; got %v", err)
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		t.Fatalf("expected the error to wrap *pq.Error; got %v", err)
+	}
+
+	if err := ClassifyError(&pq.Error{Code: "23503"}); !errors.Is(err, ErrForeignKeyViolation) {
+		t.Fatalf("expected ErrForeignKeyViolation; got %v", err)
+	}
+	if err := ClassifyError(&pq.Error{Code: "42P01"}); errors.Is(err, ErrUniqueViolation) || errors.Is(err, ErrForeignKeyViolation) {
+		t.Fatalf("expected an unclassified error; got %v", err)
+	}
+}
+
+func TestPair(t *testing.T) {
+	var p Pair
+	if err := p.Scan([]byte("(1,hello)")); err != nil {
+		t.Fatal(err)
+	}
+	expected := Pair{
+		ID:    NullInt32{sql.NullInt32{Int32: 1, Valid: true}},
+		Label: NullString{sql.NullString{String: "hello", Valid: true}},
+	}
+	if p != expected {
+		t.Fatalf("scanned %+v; expected %+v", p, expected)
+	}
+	if err := p.Scan([]byte(`(,"say ""hi"", \\o/")`)); err != nil {
+		t.Fatal(err)
+	}
+	if p.ID.Valid || p.Label.String != `say "hi", \o/` {
+		t.Fatalf("scanned %+v", p)
+	}
+	v, err := p.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != `(,"say \"hi\", \\o/")` {
+		t.Fatalf("encoded %v", v)
+	}
+}
+
+func TestNullInt32JSON(t *testing.T) {
+	for _, tc := range []struct {
+		value NullInt32
+		json  string
+	}{
+		{NullInt32{sql.NullInt32{Int32: 1, Valid: true}}, "1"},
+		{NullInt32{}, "null"},
+	} {
+		b, err := json.Marshal(tc.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.json {
+			t.Errorf("marshaled %+v to %s; expected %s", tc.value, b, tc.json)
+		}
+		var n NullInt32
+		if err := json.Unmarshal(b, &n); err != nil {
+			t.Fatal(err)
+		}
+		if n != tc.value {
+			t.Errorf("unmarshaled %s to %+v; expected %+v", b, n, tc.value)
+		}
+	}
+
+	b, err := json.Marshal(listFooNamesParams{Name: "alice", Bio: NullString{sql.NullString{String: "hi", Valid: true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"name":"alice","bio":"hi"}`; string(b) != expected {
+		t.Errorf("marshaled %s; expected %s", b, expected)
+	}
+}
+
+func TestMoodJSON(t *testing.T) {
+	b, err := json.Marshal(MoodHappy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"happy"` {
+		t.Fatalf("marshaled %s", b)
+	}
+	var m Mood
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m != MoodHappy {
+		t.Fatalf("unmarshaled %s to %q", b, m)
+	}
+	if err := json.Unmarshal([]byte(`"angry"`), &m); err == nil {
+		t.Fatal("expected an error for an unknown label")
+	}
+}
+
+func TestValidFooStatus(t *testing.T) {
+	for _, s := range []string{FooStatusPending, FooStatusShipped} {
+		if !ValidFooStatus(s) {
+			t.Errorf("%q is not valid", s)
+		}
+	}
+	if ValidFooStatus("lost") {
+		t.Errorf("%q is valid", "lost")
+	}
+}
+
+func TestMockQuerier(t *testing.T) {
+	var q Querier = &MockQuerier{
+		getFooFunc: func(ctx context.Context, id int32) (*Foo, error) {
+			return &Foo{ID: id, Name: "foo"}, nil
+		},
+	}
+	m := q.(*MockQuerier)
+
+	foo, err := q.getFoo(context.Background(), 1)
+	if err != nil || foo.Name != "foo" {
+		t.Fatalf("getFoo returned %v, %v", foo, err)
+	}
+	if len(m.getFooCalls) != 1 || m.getFooCalls[0][0] != int32(1) {
+		t.Errorf("getFoo calls not recorded: %v", m.getFooCalls)
+	}
+
+	n, err := q.updateFoo(context.Background(), updateFooParams{ID: 2, Name: "bar"})
+	if err != nil || n != 0 {
+		t.Fatalf("updateFoo returned %v, %v", n, err)
+	}
+	if len(m.updateFooCalls) != 1 || m.updateFooCalls[0][0] != (updateFooParams{ID: 2, Name: "bar"}) {
+		t.Errorf("updateFoo calls not recorded: %v", m.updateFooCalls)
+	}
+
+	if _, err := q.Exec(context.Background(), "SELECT $1", 3); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.ExecCalls) != 1 || m.ExecCalls[0][0] != "SELECT $1" || m.ExecCalls[0][1] != 3 {
+		t.Errorf("Exec calls not recorded: %v", m.ExecCalls)
+	}
+	if len(m.listFoosCalls) != 0 || len(m.PingCalls) != 0 {
+		t.Errorf("unexpected calls recorded")
+	}
+}
+
+type nopDB struct {
+	DBTX
+}
+
+func (nopDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func TestQueryHook(t *testing.T) {
+	var name, query string
+	QueryHook = func(ctx context.Context, n, q string) {
+		name, query = n, q
+	}
+	defer func() { QueryHook = nil }()
+
+	if err := New(nopDB{}).deleteFoo(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if name != "deleteFoo" || query != deleteFooQuery {
+		t.Errorf("hook called with %q, %q", name, query)
+	}
+}
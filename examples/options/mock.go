@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+
+package options
+
+import (
+	"context"
+	"database/sql"
+)
+
+// MockQuerier stands in for Queries in tests. Each method records its
+// arguments in the matching Calls field and returns the result of the matching
+// Func field, or zero values when it is nil. It is not safe for concurrent use.
+type MockQuerier struct {
+	PingFunc            func(ctx context.Context) error
+	PingCalls           [][]interface{}
+	ExecFunc            func(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	ExecCalls           [][]interface{}
+	createFooFunc       func(ctx context.Context, arg createFooParams) (*Foo, error)
+	createFooCalls      [][]interface{}
+	deleteFooFunc       func(ctx context.Context, id int32) error
+	deleteFooCalls      [][]interface{}
+	getFooFunc          func(ctx context.Context, id int32) (*Foo, error)
+	getFooCalls         [][]interface{}
+	getFooNameFunc      func(ctx context.Context, id int32) (string, error)
+	getFooNameCalls     [][]interface{}
+	listFooNamesFunc    func(ctx context.Context, arg listFooNamesParams) ([]listFooNamesRow, error)
+	listFooNamesCalls   [][]interface{}
+	listFoosFunc        func(ctx context.Context) ([]Foo, error)
+	listFoosCalls       [][]interface{}
+	updateFooFunc       func(ctx context.Context, arg updateFooParams) (int64, error)
+	updateFooCalls      [][]interface{}
+	updateSettingsFunc  func(ctx context.Context, arg updateSettingsParams) error
+	updateSettingsCalls [][]interface{}
+}
+
+func (m *MockQuerier) Ping(ctx context.Context) error {
+	m.PingCalls = append(m.PingCalls, []interface{}{})
+	if m.PingFunc == nil {
+		return nil
+	}
+	return m.PingFunc(ctx)
+}
+
+func (m *MockQuerier) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	m.ExecCalls = append(m.ExecCalls, append([]interface{}{query}, args...))
+	if m.ExecFunc == nil {
+		return nil, nil
+	}
+	return m.ExecFunc(ctx, query, args...)
+}
+
+func (m *MockQuerier) createFoo(ctx context.Context, arg createFooParams) (*Foo, error) {
+	m.createFooCalls = append(m.createFooCalls, []interface{}{arg})
+	if m.createFooFunc == nil {
+		var zero *Foo
+		return zero, nil
+	}
+	return m.createFooFunc(ctx, arg)
+}
+
+func (m *MockQuerier) deleteFoo(ctx context.Context, id int32) error {
+	m.deleteFooCalls = append(m.deleteFooCalls, []interface{}{id})
+	if m.deleteFooFunc == nil {
+		return nil
+	}
+	return m.deleteFooFunc(ctx, id)
+}
+
+func (m *MockQuerier) getFoo(ctx context.Context, id int32) (*Foo, error) {
+	m.getFooCalls = append(m.getFooCalls, []interface{}{id})
+	if m.getFooFunc == nil {
+		var zero *Foo
+		return zero, nil
+	}
+	return m.getFooFunc(ctx, id)
+}
+
+func (m *MockQuerier) getFooName(ctx context.Context, id int32) (string, error) {
+	m.getFooNameCalls = append(m.getFooNameCalls, []interface{}{id})
+	if m.getFooNameFunc == nil {
+		var zero string
+		return zero, nil
+	}
+	return m.getFooNameFunc(ctx, id)
+}
+
+func (m *MockQuerier) listFooNames(ctx context.Context, arg listFooNamesParams) ([]listFooNamesRow, error) {
+	m.listFooNamesCalls = append(m.listFooNamesCalls, []interface{}{arg})
+	if m.listFooNamesFunc == nil {
+		return nil, nil
+	}
+	return m.listFooNamesFunc(ctx, arg)
+}
+
+func (m *MockQuerier) listFoos(ctx context.Context) ([]Foo, error) {
+	m.listFoosCalls = append(m.listFoosCalls, []interface{}{})
+	if m.listFoosFunc == nil {
+		return nil, nil
+	}
+	return m.listFoosFunc(ctx)
+}
+
+func (m *MockQuerier) updateFoo(ctx context.Context, arg updateFooParams) (int64, error) {
+	m.updateFooCalls = append(m.updateFooCalls, []interface{}{arg})
+	if m.updateFooFunc == nil {
+		return 0, nil
+	}
+	return m.updateFooFunc(ctx, arg)
+}
+
+func (m *MockQuerier) updateSettings(ctx context.Context, arg updateSettingsParams) error {
+	m.updateSettingsCalls = append(m.updateSettingsCalls, []interface{}{arg})
+	if m.updateSettingsFunc == nil {
+		return nil
+	}
+	return m.updateSettingsFunc(ctx, arg)
+}
+
+var _ Querier = (*MockQuerier)(nil)
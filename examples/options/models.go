@@ -0,0 +1,403 @@
+// Code generated by sqlc. DO NOT EDIT.
+
+package options
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kyleconroy/sqlc/examples/options/blob"
+	"github.com/kyleconroy/sqlc/examples/options/settings"
+)
+
+type Mood string
+
+const (
+	MoodHappy Mood = "happy"
+	MoodSad   Mood = "sad"
+)
+
+func (e *Mood) Scan(src interface{}) error {
+	*e = Mood(src.([]byte))
+	return nil
+}
+
+func (e Mood) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(e))
+}
+
+func (e *Mood) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if Mood(s).Valid() {
+		*e = Mood(s)
+		return nil
+	}
+	return fmt.Errorf("invalid Mood value %q", s)
+}
+
+// Valid reports whether e is one of the Mood values
+func (e Mood) Valid() bool {
+	switch e {
+	case MoodHappy, MoodSad:
+		return true
+	}
+	return false
+}
+
+// Values allowed by the CHECK constraint on foo.status
+const (
+	FooStatusPending = "pending"
+	FooStatusShipped = "shipped"
+)
+
+// ValidFooStatus reports whether s is allowed by the CHECK constraint on foo.status
+func ValidFooStatus(s string) bool {
+	switch s {
+	case FooStatusPending, FooStatusShipped:
+		return true
+	}
+	return false
+}
+
+type Foo struct {
+	ID       int32             `json:"id"`
+	Name     string            `json:"name"`
+	Bio      NullString        `json:"bio"`
+	Count    NullInt32         `json:"count"`
+	Tags     []string          `json:"tags"`
+	Data     []byte            `json:"data"`
+	Thumb    blob.Blob         `json:"thumb"`
+	Settings settings.Settings `json:"settings"`
+	Mood     Mood              `json:"mood"`
+	Status   string            `json:"status"`
+	P        Pair              `json:"p"`
+}
+
+func (v Foo) String() string {
+	return fmt.Sprintf("Foo{ID:%v Name:%v Bio:%v Count:%v Tags:%v Data:%v Thumb:%v Settings:%v Mood:%v Status:%v P:%v}", v.ID, v.Name, v.Bio, v.Count, v.Tags, v.Data, v.Thumb, v.Settings, v.Mood, v.Status, v.P)
+}
+
+// DeepCopy returns a copy of v that shares no slices or pointers with it
+func (v Foo) DeepCopy() Foo {
+	c := v
+	if v.Tags != nil {
+		c.Tags = make([]string, len(v.Tags))
+		copy(c.Tags, v.Tags)
+	}
+	if v.Data != nil {
+		c.Data = make([]byte, len(v.Data))
+		copy(c.Data, v.Data)
+	}
+	return c
+}
+
+type Pair struct {
+	ID    NullInt32  `json:"id"`
+	Label NullString `json:"label"`
+}
+
+func (v Pair) String() string {
+	return fmt.Sprintf("Pair{ID:%v Label:%v}", v.ID, v.Label)
+}
+
+// DeepCopy returns a copy of v that shares no slices or pointers with it
+func (v Pair) DeepCopy() Pair {
+	c := v
+	return c
+}
+
+func (c *Pair) Scan(src interface{}) error {
+	if src == nil {
+		*c = Pair{}
+		return nil
+	}
+	fields, err := parseComposite(src)
+	if err != nil {
+		return err
+	}
+	if len(fields) != 2 {
+		return fmt.Errorf("Pair: expected 2 fields, got %d", len(fields))
+	}
+	if err := scanCompositeField(&c.ID, fields[0]); err != nil {
+		return fmt.Errorf("Pair.ID: %w", err)
+	}
+	if err := scanCompositeField(&c.Label, fields[1]); err != nil {
+		return fmt.Errorf("Pair.Label: %w", err)
+	}
+	return nil
+}
+
+func (c Pair) Value() (driver.Value, error) {
+	return formatComposite(c.ID, c.Label)
+}
+
+// NullBool is a sql.NullBool that marshals to JSON as its value or null
+type NullBool struct {
+	sql.NullBool
+}
+
+func (n NullBool) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Bool)
+}
+
+func (n *NullBool) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullBool{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Bool); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullFloat64 is a sql.NullFloat64 that marshals to JSON as its value or null
+type NullFloat64 struct {
+	sql.NullFloat64
+}
+
+func (n NullFloat64) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Float64)
+}
+
+func (n *NullFloat64) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullFloat64{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Float64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullInt32 is a sql.NullInt32 that marshals to JSON as its value or null
+type NullInt32 struct {
+	sql.NullInt32
+}
+
+func (n NullInt32) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Int32)
+}
+
+func (n *NullInt32) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullInt32{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Int32); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullInt64 is a sql.NullInt64 that marshals to JSON as its value or null
+type NullInt64 struct {
+	sql.NullInt64
+}
+
+func (n NullInt64) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Int64)
+}
+
+func (n *NullInt64) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullInt64{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Int64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullString is a sql.NullString that marshals to JSON as its value or null
+type NullString struct {
+	sql.NullString
+}
+
+func (n NullString) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.String)
+}
+
+func (n *NullString) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullString{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.String); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullTime is a sql.NullTime that marshals to JSON as its value or null
+type NullTime struct {
+	sql.NullTime
+}
+
+func (n NullTime) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Time)
+}
+
+func (n *NullTime) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = NullTime{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Time); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// parseComposite splits the text form of a composite value, e.g. (1,hello),
+// into its fields. NULL fields are returned as nil.
+func parseComposite(src interface{}) ([]*string, error) {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return nil, fmt.Errorf("cannot scan %T into a composite type", src)
+	}
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("invalid composite value %q", s)
+	}
+	var fields []*string
+	var field strings.Builder
+	var quoted, inQuotes, escaped bool
+	flush := func() {
+		if field.Len() == 0 && !quoted {
+			fields = append(fields, nil)
+		} else {
+			v := field.String()
+			fields = append(fields, &v)
+		}
+		field.Reset()
+		quoted = false
+	}
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case escaped:
+			field.WriteByte(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case inQuotes && c == '"':
+			if i+1 < len(body) && body[i+1] == '"' {
+				field.WriteByte('"')
+				i++
+			} else {
+				inQuotes = false
+			}
+		case c == '"':
+			inQuotes, quoted = true, true
+		case !inQuotes && c == ',':
+			flush()
+		default:
+			field.WriteByte(c)
+		}
+	}
+	flush()
+	return fields, nil
+}
+
+// scanCompositeField converts a single composite field into dest
+func scanCompositeField(dest interface{}, src *string) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		if src == nil {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan([]byte(*src))
+	}
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dest)
+	}
+	var err error
+	switch d := dest.(type) {
+	case *string:
+		*d = *src
+	case *bool:
+		*d, err = strconv.ParseBool(*src)
+	case *int16:
+		var v int64
+		v, err = strconv.ParseInt(*src, 10, 16)
+		*d = int16(v)
+	case *int32:
+		var v int64
+		v, err = strconv.ParseInt(*src, 10, 32)
+		*d = int32(v)
+	case *int64:
+		*d, err = strconv.ParseInt(*src, 10, 64)
+	case *float32:
+		var v float64
+		v, err = strconv.ParseFloat(*src, 32)
+		*d = float32(v)
+	case *float64:
+		*d, err = strconv.ParseFloat(*src, 64)
+	default:
+		return fmt.Errorf("cannot scan composite field into %T", dest)
+	}
+	return err
+}
+
+// formatComposite encodes values in the text form of a composite value
+func formatComposite(values ...interface{}) (driver.Value, error) {
+	quote := strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+	fields := make([]string, len(values))
+	for i, v := range values {
+		if valuer, ok := v.(driver.Valuer); ok {
+			var err error
+			if v, err = valuer.Value(); err != nil {
+				return nil, err
+			}
+		}
+		switch t := v.(type) {
+		case nil:
+			continue
+		case bool:
+			fields[i] = strconv.FormatBool(t)
+		case []byte:
+			fields[i] = "\"" + quote.Replace(string(t)) + "\""
+		default:
+			fields[i] = "\"" + quote.Replace(fmt.Sprint(t)) + "\""
+		}
+	}
+	return "(" + strings.Join(fields, ",") + ")", nil
+}
@@ -0,0 +1,35 @@
+// Code generated by sqlc. DO NOT EDIT.
+
+package options
+
+const createFooQuery = `-- name: createFoo :one
+INSERT INTO foo (name, bio, count, tags, data, thumb, settings, mood, status, p) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, name, bio, count, tags, data, thumb, settings, mood, status, p
+`
+
+const deleteFooQuery = `-- name: deleteFoo :exec
+DELETE FROM foo WHERE id = $1
+`
+
+const getFooQuery = `-- name: getFoo :one
+SELECT id, name, bio, count, tags, data, thumb, settings, mood, status, p FROM foo WHERE id = $1
+`
+
+const getFooNameQuery = `-- name: getFooName :one
+SELECT name FROM foo WHERE id = $1
+`
+
+const listFooNamesQuery = `-- name: listFooNames :many
+SELECT id, name FROM foo WHERE name = $1 OR bio = $2
+`
+
+const listFoosQuery = `-- name: listFoos :many
+SELECT id, name, bio, count, tags, data, thumb, settings, mood, status, p FROM foo ORDER BY id
+`
+
+const updateFooQuery = `-- name: updateFoo :execrows
+UPDATE foo SET name = $2 WHERE id = $1
+`
+
+const updateSettingsQuery = `-- name: updateSettings :exec
+UPDATE foo SET settings = $2 WHERE id = $1
+`
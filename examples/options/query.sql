@@ -0,0 +1,17 @@
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: GetFooName :one
+SELECT name FROM foo WHERE id = $1;
+
+-- name: ListFooNames :many
+SELECT id, name FROM foo WHERE name = $1 OR bio = $2;
+
+-- name: UpdateFoo :execrows
+UPDATE foo SET name = $2 WHERE id = $1;
+
+-- name: UpdateSettings :exec
+UPDATE foo SET settings = $2 WHERE id = $1;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
@@ -0,0 +1,146 @@
+// Code generated by sqlc. DO NOT EDIT.
+// source: query.sql
+
+package options
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kyleconroy/sqlc/examples/options/settings"
+	"github.com/lib/pq"
+)
+
+func (q *Queries) deleteFoo(ctx context.Context, id int32) error {
+	if QueryHook != nil {
+		QueryHook(ctx, "deleteFoo", deleteFooQuery)
+	}
+	_, err := q.exec(ctx, q.deleteFooPrepared, deleteFooQuery, id)
+	return err
+}
+
+func (q *Queries) getFoo(ctx context.Context, id int32) (*Foo, error) {
+	if QueryHook != nil {
+		QueryHook(ctx, "getFoo", getFooQuery)
+	}
+	row := q.queryRow(ctx, q.getFooPrepared, getFooQuery, id)
+	var i Foo
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Bio,
+		&i.Count,
+		pq.Array(&i.Tags),
+		&i.Data,
+		&i.Thumb,
+		jsonValue{&i.Settings},
+		&i.Mood,
+		&i.Status,
+		&i.P,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (q *Queries) getFooName(ctx context.Context, id int32) (string, error) {
+	if QueryHook != nil {
+		QueryHook(ctx, "getFooName", getFooNameQuery)
+	}
+	row := q.queryRow(ctx, q.getFooNamePrepared, getFooNameQuery, id)
+	var name string
+	err := row.Scan(&name)
+	return name, err
+}
+
+type listFooNamesParams struct {
+	Name string     `json:"name"`
+	Bio  NullString `json:"bio"`
+}
+
+func (v listFooNamesParams) String() string {
+	return fmt.Sprintf("listFooNamesParams{Name:%v Bio:%v}", v.Name, v.Bio)
+}
+
+type listFooNamesRow struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (v listFooNamesRow) String() string {
+	return fmt.Sprintf("listFooNamesRow{ID:%v Name:%v}", v.ID, v.Name)
+}
+
+// DeepCopy returns a copy of v that shares no slices or pointers with it
+func (v listFooNamesRow) DeepCopy() listFooNamesRow {
+	c := v
+	return c
+}
+
+func (q *Queries) listFooNames(ctx context.Context, arg listFooNamesParams) ([]listFooNamesRow, error) {
+	if QueryHook != nil {
+		QueryHook(ctx, "listFooNames", listFooNamesQuery)
+	}
+	rows, err := q.query(ctx, q.listFooNamesPrepared, listFooNamesQuery, arg.Name, arg.Bio)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []listFooNamesRow
+	for rows.Next() {
+		var i listFooNamesRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+type updateFooParams struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (v updateFooParams) String() string {
+	return fmt.Sprintf("updateFooParams{ID:%v Name:%v}", v.ID, v.Name)
+}
+
+func (q *Queries) updateFoo(ctx context.Context, arg updateFooParams) (int64, error) {
+	if QueryHook != nil {
+		QueryHook(ctx, "updateFoo", updateFooQuery)
+	}
+	result, err := q.exec(ctx, q.updateFooPrepared, updateFooQuery, arg.ID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+type updateSettingsParams struct {
+	ID       int32             `json:"id"`
+	Settings settings.Settings `json:"settings"`
+}
+
+func (v updateSettingsParams) String() string {
+	return fmt.Sprintf("updateSettingsParams{ID:%v Settings:%v}", v.ID, v.Settings)
+}
+
+func (q *Queries) updateSettings(ctx context.Context, arg updateSettingsParams) error {
+	if QueryHook != nil {
+		QueryHook(ctx, "updateSettings", updateSettingsQuery)
+	}
+	_, err := q.exec(ctx, q.updateSettingsPrepared, updateSettingsQuery, arg.ID, jsonValue{arg.Settings})
+	return err
+}
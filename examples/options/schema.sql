@@ -0,0 +1,17 @@
+CREATE TYPE mood AS ENUM ('happy', 'sad');
+
+CREATE TYPE pair AS (id int, label text);
+
+CREATE TABLE foo (
+    id       SERIAL PRIMARY KEY,
+    name     text   NOT NULL,
+    bio      text,
+    count    integer,
+    tags     text[] NOT NULL,
+    data     bytea,
+    thumb    bytea  NOT NULL,
+    settings jsonb  NOT NULL,
+    mood     mood   NOT NULL,
+    status   text   NOT NULL CHECK (status IN ('pending', 'shipped')),
+    p        pair
+);
@@ -0,0 +1,6 @@
+// Package settings holds the type stored as JSON in the foo.settings column
+package settings
+
+type Settings struct {
+	Theme string `json:"theme"`
+}
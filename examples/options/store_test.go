@@ -0,0 +1,57 @@
+package options_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/kyleconroy/sqlc/examples/options"
+)
+
+var commits, rollbacks int
+
+// txDriver counts the transactions it commits and rolls back
+type txDriver struct{}
+
+func (txDriver) Open(string) (driver.Conn, error) { return txConn{}, nil }
+
+type txConn struct{}
+
+func (txConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (txConn) Close() error                        { return nil }
+func (txConn) Begin() (driver.Tx, error)           { return countingTx{}, nil }
+
+type countingTx struct{}
+
+func (countingTx) Commit() error   { commits++; return nil }
+func (countingTx) Rollback() error { rollbacks++; return nil }
+
+func init() {
+	sql.Register("tx", txDriver{})
+}
+
+func TestExecTx(t *testing.T) {
+	db, err := sql.Open("tx", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := options.NewStore(db)
+	ctx := context.Background()
+
+	if err := s.ExecTx(ctx, func(q *options.Queries) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if commits != 1 || rollbacks != 0 {
+		t.Errorf("commits = %d, rollbacks = %d after success", commits, rollbacks)
+	}
+
+	failed := errors.New("failed")
+	if err := s.ExecTx(ctx, func(q *options.Queries) error { return failed }); err != failed {
+		t.Errorf("ExecTx returned %v", err)
+	}
+	if commits != 1 || rollbacks != 1 {
+		t.Errorf("commits = %d, rollbacks = %d after error", commits, rollbacks)
+	}
+}
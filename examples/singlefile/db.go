@@ -0,0 +1,96 @@
+// Copyright 2020 The sqlc Authors
+// Code generated by sqlc. DO NOT EDIT.
+
+package singlefile
+
+import (
+	"context"
+	"database/sql"
+)
+
+type DBTX interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+func New(db DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+type Queries struct {
+	db DBTX
+}
+
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db: tx,
+	}
+}
+
+type Foo struct {
+	ID   int32
+	Name string
+	Bio  sql.NullString
+}
+
+type Person struct {
+	Name string
+	Mood Mood
+}
+
+type Mood string
+
+const (
+	MoodHappy Mood = "happy"
+	MoodSad   Mood = "sad"
+)
+
+func (e *Mood) Scan(src interface{}) error {
+	*e = Mood(src.([]byte))
+	return nil
+}
+
+// source: query.sql
+
+const getFoo = `-- name: GetFoo :one
+SELECT id, name, bio FROM foo WHERE id = $1
+`
+
+func (q *Queries) GetFoo(ctx context.Context, id int32) (Foo, error) {
+	row := q.db.QueryRowContext(ctx, getFoo, id)
+	var i Foo
+	err := row.Scan(&i.ID, &i.Name, &i.Bio)
+	if err == sql.ErrNoRows {
+		return i, nil
+	}
+	return i, err
+}
+
+const listPeople = `-- name: ListPeople :many
+SELECT name, mood FROM person WHERE mood = $1
+`
+
+func (q *Queries) ListPeople(ctx context.Context, mood Mood) ([]Person, error) {
+	rows, err := q.db.QueryContext(ctx, listPeople, mood)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Person
+	for rows.Next() {
+		var i Person
+		if err := rows.Scan(&i.Name, &i.Mood); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
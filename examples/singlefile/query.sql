@@ -0,0 +1,5 @@
+-- name: ListPeople :many
+SELECT * FROM person WHERE mood = $1;
+
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
@@ -0,0 +1,12 @@
+CREATE TYPE mood AS ENUM ('happy', 'sad');
+
+CREATE TABLE person (
+    name text NOT NULL,
+    mood mood NOT NULL
+);
+
+CREATE TABLE foo (
+    id   SERIAL PRIMARY KEY,
+    name text   NOT NULL,
+    bio  text
+);
@@ -107,6 +107,8 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 					implemented = true
 				case nodes.AT_AlterColumnType:
 					implemented = true
+				case nodes.AT_ColumnDefault:
+					implemented = true
 				case nodes.AT_DropColumn:
 					implemented = true
 				case nodes.AT_DropNotNull:
@@ -141,6 +143,7 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 				// Lookup column names for column-related commands
 				switch cmd.Subtype {
 				case nodes.AT_AlterColumnType,
+					nodes.AT_ColumnDefault,
 					nodes.AT_DropColumn,
 					nodes.AT_DropNotNull,
 					nodes.AT_SetNotNull:
@@ -170,18 +173,28 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 						}
 					}
 					table.Columns = append(table.Columns, pg.Column{
-						Name:     *d.Colname,
-						DataType: join(d.TypeName.Names, "."),
-						NotNull:  isNotNull(d),
-						IsArray:  isArray(d.TypeName),
-						Table:    fqn,
+						Name:       *d.Colname,
+						DataType:   join(d.TypeName.Names, "."),
+						NotNull:    isNotNull(d),
+						IsArray:    isArray(d.TypeName),
+						PrimaryKey: isPrimaryKey(d),
+						HasDefault: hasDefault(d),
+						ForeignKey: foreignKey(d),
+						Table:      fqn,
 					})
+					if col, vals, ok := columnCheck(d.Constraints); ok && col == *d.Colname {
+						table.Columns[len(table.Columns)-1].CheckValues = vals
+					}
 
 				case nodes.AT_AlterColumnType:
 					d := cmd.Def.(nodes.ColumnDef)
 					table.Columns[idx].DataType = join(d.TypeName.Names, ".")
 					table.Columns[idx].IsArray = isArray(d.TypeName)
 
+				case nodes.AT_ColumnDefault:
+					// SET DEFAULT has an expression and DROP DEFAULT doesn't
+					table.Columns[idx].HasDefault = cmd.Def != nil
+
 				case nodes.AT_DropColumn:
 					table.Columns = append(table.Columns[:idx], table.Columns[idx+1:]...)
 
@@ -217,12 +230,67 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 			case nodes.ColumnDef:
 				colName := *n.Colname
 				table.Columns = append(table.Columns, pg.Column{
-					Name:     colName,
-					DataType: join(n.TypeName.Names, "."),
-					NotNull:  isNotNull(n),
-					IsArray:  isArray(n.TypeName),
-					Table:    fqn,
+					Name:       colName,
+					DataType:   join(n.TypeName.Names, "."),
+					NotNull:    isNotNull(n),
+					IsArray:    isArray(n.TypeName),
+					PrimaryKey: isPrimaryKey(n),
+					HasDefault: hasDefault(n),
+					ForeignKey: foreignKey(n),
+					Table:      fqn,
 				})
+				if col, vals, ok := columnCheck(n.Constraints); ok && col == *n.Colname {
+					table.Columns[len(table.Columns)-1].CheckValues = vals
+				}
+			}
+		}
+		// A table constraint, e.g. PRIMARY KEY (a, b), applies to columns
+		// defined anywhere in the statement
+		for _, elt := range n.TableElts.Items {
+			con, ok := elt.(nodes.Constraint)
+			if !ok {
+				continue
+			}
+			switch con.Contype {
+			case nodes.CONSTR_PRIMARY:
+				for _, key := range stringSlice(con.Keys) {
+					for i := range table.Columns {
+						if table.Columns[i].Name == key {
+							table.Columns[i].NotNull = true
+							table.Columns[i].PrimaryKey = true
+						}
+					}
+				}
+			case nodes.CONSTR_CHECK:
+				col, vals, ok := checkValues(con.RawExpr)
+				if !ok {
+					continue
+				}
+				for i := range table.Columns {
+					if table.Columns[i].Name == col {
+						table.Columns[i].CheckValues = vals
+					}
+				}
+			case nodes.CONSTR_FOREIGN:
+				if con.Pktable == nil {
+					continue
+				}
+				ref, err := ParseRange(con.Pktable)
+				if err != nil {
+					return err
+				}
+				refCols := stringSlice(con.PkAttrs)
+				for j, key := range stringSlice(con.FkAttrs) {
+					fk := &pg.ForeignKey{Table: ref}
+					if j < len(refCols) {
+						fk.Column = refCols[j]
+					}
+					for i := range table.Columns {
+						if table.Columns[i].Name == key {
+							table.Columns[i].ForeignKey = fk
+						}
+					}
+				}
 			}
 		}
 		schema.Tables[fqn.Rel] = table
@@ -239,11 +307,43 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 		if _, exists := schema.Enums[fqn.Rel]; exists {
 			return wrap(pg.ErrorTypeAlreadyExists(fqn.Rel), raw.StmtLocation)
 		}
+		if _, exists := schema.CompositeTypes[fqn.Rel]; exists {
+			return wrap(pg.ErrorTypeAlreadyExists(fqn.Rel), raw.StmtLocation)
+		}
 		schema.Enums[fqn.Rel] = pg.Enum{
 			Name: fqn.Rel,
 			Vals: stringSlice(n.Vals),
 		}
 
+	case nodes.CompositeTypeStmt:
+		fqn, err := ParseRange(n.Typevar)
+		if err != nil {
+			return err
+		}
+		schema, exists := c.Schemas[fqn.Schema]
+		if !exists {
+			return wrap(pg.ErrorSchemaDoesNotExist(fqn.Schema), raw.StmtLocation)
+		}
+		if _, exists := schema.Enums[fqn.Rel]; exists {
+			return wrap(pg.ErrorTypeAlreadyExists(fqn.Rel), raw.StmtLocation)
+		}
+		if _, exists := schema.CompositeTypes[fqn.Rel]; exists {
+			return wrap(pg.ErrorTypeAlreadyExists(fqn.Rel), raw.StmtLocation)
+		}
+		typ := pg.CompositeType{
+			Name: fqn.Rel,
+		}
+		for _, elt := range n.Coldeflist.Items {
+			if d, ok := elt.(nodes.ColumnDef); ok {
+				typ.Columns = append(typ.Columns, pg.Column{
+					Name:     *d.Colname,
+					DataType: join(d.TypeName.Names, "."),
+					IsArray:  isArray(d.TypeName),
+				})
+			}
+		}
+		schema.CompositeTypes[fqn.Rel] = typ
+
 	case nodes.CreateSchemaStmt:
 		name := *n.Schemaname
 		if _, exists := c.Schemas[name]; exists {
@@ -253,7 +353,7 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 
 	case nodes.DropStmt:
 		for _, obj := range n.Objects.Items {
-			if n.RemoveType == nodes.OBJECT_TABLE || n.RemoveType == nodes.OBJECT_TYPE {
+			if n.RemoveType == nodes.OBJECT_TABLE || n.RemoveType == nodes.OBJECT_VIEW || n.RemoveType == nodes.OBJECT_MATVIEW || n.RemoveType == nodes.OBJECT_TYPE {
 				var fqn pg.FQN
 				var err error
 
@@ -275,7 +375,7 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 				}
 
 				switch n.RemoveType {
-				case nodes.OBJECT_TABLE:
+				case nodes.OBJECT_TABLE, nodes.OBJECT_VIEW, nodes.OBJECT_MATVIEW:
 					if _, exists := schema.Tables[fqn.Rel]; exists {
 						delete(schema.Tables, fqn.Rel)
 					} else if !n.MissingOk {
@@ -285,6 +385,8 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 				case nodes.OBJECT_TYPE:
 					if _, exists := schema.Enums[fqn.Rel]; exists {
 						delete(schema.Enums, fqn.Rel)
+					} else if _, exists := schema.CompositeTypes[fqn.Rel]; exists {
+						delete(schema.CompositeTypes, fqn.Rel)
 					} else if !n.MissingOk {
 						return wrap(pg.ErrorTypeDoesNotExist(fqn.Rel), raw.StmtLocation)
 					}
@@ -479,15 +581,20 @@ func Update(c *pg.Catalog, stmt nodes.Node) error {
 			if !exists {
 				return wrap(pg.ErrorSchemaDoesNotExist(fqn.Schema), raw.StmtLocation)
 			}
+			var comment string
+			if n.Comment != nil {
+				comment = *n.Comment
+			}
+			if typ, exists := schema.CompositeTypes[fqn.Rel]; exists {
+				typ.Comment = comment
+				schema.CompositeTypes[fqn.Rel] = typ
+				break
+			}
 			enum, exists := schema.Enums[fqn.Rel]
 			if !exists {
 				return wrap(pg.ErrorRelationDoesNotExist(fqn.Rel), raw.StmtLocation)
 			}
-			if n.Comment != nil {
-				enum.Comment = *n.Comment
-			} else {
-				enum.Comment = ""
-			}
+			enum.Comment = comment
 			schema.Enums[fqn.Rel] = enum
 
 		}
@@ -535,6 +642,114 @@ func isNotNull(n nodes.ColumnDef) bool {
 	return false
 }
 
+func isPrimaryKey(n nodes.ColumnDef) bool {
+	for _, c := range n.Constraints.Items {
+		if c, ok := c.(nodes.Constraint); ok && c.Contype == nodes.CONSTR_PRIMARY {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDefault reports whether the column has a DEFAULT value or is an identity
+// column, which PostgreSQL fills in when an INSERT omits it
+func hasDefault(n nodes.ColumnDef) bool {
+	for _, c := range n.Constraints.Items {
+		if c, ok := c.(nodes.Constraint); ok && (c.Contype == nodes.CONSTR_DEFAULT || c.Contype == nodes.CONSTR_IDENTITY) {
+			return true
+		}
+	}
+	return false
+}
+
+// foreignKey returns the target of the column's REFERENCES constraint, or nil
+// if it has none
+func foreignKey(n nodes.ColumnDef) *pg.ForeignKey {
+	for _, c := range n.Constraints.Items {
+		c, ok := c.(nodes.Constraint)
+		if !ok || c.Contype != nodes.CONSTR_FOREIGN || c.Pktable == nil {
+			continue
+		}
+		ref, err := ParseRange(c.Pktable)
+		if err != nil {
+			return nil
+		}
+		fk := &pg.ForeignKey{Table: ref}
+		if cols := stringSlice(c.PkAttrs); len(cols) > 0 {
+			fk.Column = cols[0]
+		}
+		return fk
+	}
+	return nil
+}
+
+// columnCheck returns the values allowed by the first CHECK constraint in
+// constraints that limits a column to a list of strings
+func columnCheck(constraints nodes.List) (string, []string, bool) {
+	for _, c := range constraints.Items {
+		c, ok := c.(nodes.Constraint)
+		if !ok || c.Contype != nodes.CONSTR_CHECK {
+			continue
+		}
+		if col, vals, ok := checkValues(c.RawExpr); ok {
+			return col, vals, true
+		}
+	}
+	return "", nil, false
+}
+
+// checkValues matches a CHECK expression of the form `col IN ('a', 'b')`, or
+// `col = ANY (ARRAY['a', 'b'])` as written by pg_dump, and returns the column
+// and the allowed values
+func checkValues(expr nodes.Node) (string, []string, bool) {
+	e, ok := expr.(nodes.A_Expr)
+	if !ok || join(e.Name, "") != "=" {
+		return "", nil, false
+	}
+	ref, ok := e.Lexpr.(nodes.ColumnRef)
+	if !ok {
+		return "", nil, false
+	}
+	parts := stringSlice(ref.Fields)
+	if len(parts) == 0 {
+		return "", nil, false
+	}
+	var list nodes.List
+	switch e.Kind {
+	case nodes.AEXPR_IN:
+		list, ok = e.Rexpr.(nodes.List)
+	case nodes.AEXPR_OP_ANY:
+		var arr nodes.A_ArrayExpr
+		if c, isCast := e.Rexpr.(nodes.TypeCast); isCast {
+			arr, ok = c.Arg.(nodes.A_ArrayExpr)
+		} else {
+			arr, ok = e.Rexpr.(nodes.A_ArrayExpr)
+		}
+		list = arr.Elements
+	default:
+		return "", nil, false
+	}
+	if !ok || len(list.Items) == 0 {
+		return "", nil, false
+	}
+	var vals []string
+	for _, item := range list.Items {
+		if c, isCast := item.(nodes.TypeCast); isCast {
+			item = c.Arg
+		}
+		con, ok := item.(nodes.A_Const)
+		if !ok {
+			return "", nil, false
+		}
+		str, ok := con.Val.(nodes.String)
+		if !ok {
+			return "", nil, false
+		}
+		vals = append(vals, str.Str)
+	}
+	return parts[len(parts)-1], vals, true
+}
+
 func ToColumn(n *nodes.TypeName) pg.Column {
 	if n == nil {
 		panic("can't build column for nil type name")
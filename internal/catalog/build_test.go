@@ -45,6 +45,39 @@ func TestUpdate(t *testing.T) {
 				},
 			},
 		},
+		{
+			`
+			CREATE TYPE pair AS (id int, label text);
+			COMMENT ON TYPE pair IS 'Pair comment';
+			`,
+			pg.Catalog{
+				Schemas: map[string]pg.Schema{
+					"public": {
+						CompositeTypes: map[string]pg.CompositeType{
+							"pair": {
+								Name:    "pair",
+								Comment: "Pair comment",
+								Columns: []pg.Column{
+									{Name: "id", DataType: "pg_catalog.int4"},
+									{Name: "label", DataType: "text"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			`
+			CREATE TYPE pair AS (id int, label text);
+			DROP TYPE pair;
+			`,
+			pg.Catalog{
+				Schemas: map[string]pg.Schema{
+					"public": {},
+				},
+			},
+		},
 		{
 			"CREATE TABLE venues ();",
 			pg.Catalog{
@@ -329,6 +362,108 @@ func TestUpdate(t *testing.T) {
 			`,
 			pg.NewCatalog(),
 		},
+		{
+			`
+			CREATE TABLE memberships (user_id int, group_id int, note text, PRIMARY KEY (user_id, group_id));
+			`,
+			pg.Catalog{
+				Schemas: map[string]pg.Schema{
+					"public": {
+						Tables: map[string]pg.Table{
+							"memberships": pg.Table{
+								Name: "memberships",
+								Columns: []pg.Column{
+									{Name: "user_id", DataType: "pg_catalog.int4", NotNull: true, PrimaryKey: true, Table: pg.FQN{Schema: "public", Rel: "memberships"}},
+									{Name: "group_id", DataType: "pg_catalog.int4", NotNull: true, PrimaryKey: true, Table: pg.FQN{Schema: "public", Rel: "memberships"}},
+									{Name: "note", DataType: "text", Table: pg.FQN{Schema: "public", Rel: "memberships"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			`
+			CREATE TABLE sessions (id uuid PRIMARY KEY DEFAULT gen_random_uuid(), seq int GENERATED ALWAYS AS IDENTITY, token text, created_at timestamptz DEFAULT now(), note text);
+			ALTER TABLE sessions ALTER COLUMN note SET DEFAULT '';
+			ALTER TABLE sessions ALTER COLUMN created_at DROP DEFAULT;
+			`,
+			pg.Catalog{
+				Schemas: map[string]pg.Schema{
+					"public": {
+						Tables: map[string]pg.Table{
+							"sessions": pg.Table{
+								Name: "sessions",
+								Columns: []pg.Column{
+									{Name: "id", DataType: "uuid", NotNull: true, PrimaryKey: true, HasDefault: true, Table: pg.FQN{Schema: "public", Rel: "sessions"}},
+									{Name: "seq", DataType: "pg_catalog.int4", HasDefault: true, Table: pg.FQN{Schema: "public", Rel: "sessions"}},
+									{Name: "token", DataType: "text", Table: pg.FQN{Schema: "public", Rel: "sessions"}},
+									{Name: "created_at", DataType: "timestamptz", Table: pg.FQN{Schema: "public", Rel: "sessions"}},
+									{Name: "note", DataType: "text", HasDefault: true, Table: pg.FQN{Schema: "public", Rel: "sessions"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			`
+			CREATE TABLE cities (slug text PRIMARY KEY);
+			CREATE TABLE venues (city text REFERENCES cities (slug), owner_id int REFERENCES accounts, region text, FOREIGN KEY (region) REFERENCES regions (code));
+			`,
+			pg.Catalog{
+				Schemas: map[string]pg.Schema{
+					"public": {
+						Tables: map[string]pg.Table{
+							"cities": pg.Table{
+								Name: "cities",
+								Columns: []pg.Column{
+									{Name: "slug", DataType: "text", NotNull: true, PrimaryKey: true, Table: pg.FQN{Schema: "public", Rel: "cities"}},
+								},
+							},
+							"venues": pg.Table{
+								Name: "venues",
+								Columns: []pg.Column{
+									{Name: "city", DataType: "text", ForeignKey: &pg.ForeignKey{Table: pg.FQN{Schema: "public", Rel: "cities"}, Column: "slug"}, Table: pg.FQN{Schema: "public", Rel: "venues"}},
+									{Name: "owner_id", DataType: "pg_catalog.int4", ForeignKey: &pg.ForeignKey{Table: pg.FQN{Schema: "public", Rel: "accounts"}}, Table: pg.FQN{Schema: "public", Rel: "venues"}},
+									{Name: "region", DataType: "text", ForeignKey: &pg.ForeignKey{Table: pg.FQN{Schema: "public", Rel: "regions"}, Column: "code"}, Table: pg.FQN{Schema: "public", Rel: "venues"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			`
+			CREATE TABLE orders (
+			  status text NOT NULL CHECK (status IN ('pending', 'shipped')),
+			  size text,
+			  color text CHECK (color = ANY (ARRAY['red'::text, 'blue'::text])),
+			  total int CHECK (total > 0),
+			  CONSTRAINT orders_size_check CHECK (size IN ('s', 'm', 'l'))
+			);
+			`,
+			pg.Catalog{
+				Schemas: map[string]pg.Schema{
+					"public": {
+						Tables: map[string]pg.Table{
+							"orders": pg.Table{
+								Name: "orders",
+								Columns: []pg.Column{
+									{Name: "status", DataType: "text", NotNull: true, CheckValues: []string{"pending", "shipped"}, Table: pg.FQN{Schema: "public", Rel: "orders"}},
+									{Name: "size", DataType: "text", CheckValues: []string{"s", "m", "l"}, Table: pg.FQN{Schema: "public", Rel: "orders"}},
+									{Name: "color", DataType: "text", CheckValues: []string{"red", "blue"}, Table: pg.FQN{Schema: "public", Rel: "orders"}},
+									{Name: "total", DataType: "pg_catalog.int4", Table: pg.FQN{Schema: "public", Rel: "orders"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			`
 			CREATE TABLE venues (id SERIAL PRIMARY KEY);
@@ -342,7 +477,7 @@ func TestUpdate(t *testing.T) {
 							"venues": pg.Table{
 								Name: "venues",
 								Columns: []pg.Column{
-									{Name: "id", DataType: "serial", NotNull: true, Table: pg.FQN{Schema: "public", Rel: "venues"}},
+									{Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true, Table: pg.FQN{Schema: "public", Rel: "venues"}},
 								},
 							},
 						},
@@ -670,6 +805,13 @@ func TestUpdateErrors(t *testing.T) {
 			`,
 			pg.Error{Code: "42703", Message: "column \"bar\" of relation \"foo\" does not exist"},
 		},
+		{
+			`
+			CREATE TYPE pair AS (id int, label text);
+			CREATE TYPE pair AS ENUM ('a', 'b');
+			`,
+			pg.Error{Code: "42710", Message: "type \"pair\" already exists"},
+		},
 		{
 			`
 			CREATE SCHEMA foo;
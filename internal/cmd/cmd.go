@@ -166,7 +166,7 @@ var genCmd = &cobra.Command{
 					continue
 				}
 
-				q, err := dinosql.ParseQueries(c, pkg)
+				q, err := dinosql.ParseQueries(c, settings, pkg)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "# package %s\n", name)
 					if parserErr, ok := err.(*dinosql.ParserErr); ok {
@@ -230,7 +230,7 @@ var checkCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
-			if _, err := dinosql.ParseQueries(c, pkg); err != nil {
+			if _, err := dinosql.ParseQueries(c, settings, pkg); err != nil {
 				return err
 			}
 		}
@@ -12,8 +12,9 @@ import (
 func TestFuncs(t *testing.T) {
 	_, err := ParseQueries(
 		pg.NewCatalog(),
+		GenerateSettings{},
 		PackageSettings{
-			Queries: filepath.Join("testdata", "funcs"),
+			Queries: Paths{filepath.Join("testdata", "funcs")},
 		},
 	)
 	if err != nil {
@@ -22,6 +23,22 @@ func TestFuncs(t *testing.T) {
 
 }
 
+func TestCallStatement(t *testing.T) {
+	_, err := ParseQueries(pg.NewCatalog(), GenerateSettings{}, PackageSettings{Queries: Paths{filepath.Join("testdata", "call")}})
+	perr, ok := err.(*ParserErr)
+	if !ok || len(perr.Errs) != 1 {
+		t.Fatalf("expected a single parser error; got %v", err)
+	}
+	ferr := perr.Errs[0]
+	if ferr.Line != 5 || ferr.Column != 1 {
+		t.Errorf("expected the error at 5:1; got %d:%d", ferr.Line, ferr.Column)
+	}
+	expected := `syntax error at or near "CALL": CALL statements are not supported yet, use SELECT with a function instead`
+	if diff := cmp.Diff(expected, ferr.Err.Error()); diff != "" {
+		t.Errorf("error mismatch: \n%s", diff)
+	}
+}
+
 func TestParserErrors(t *testing.T) {
 	for _, tc := range []struct {
 		query string
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go/token"
 	"go/types"
 	"io"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/kyleconroy/sqlc/internal/pg"
 )
@@ -47,13 +50,75 @@ type PackageSettings struct {
 	Engine              Engine     `json:"engine,omitempty"`
 	Path                string     `json:"path"`
 	Schema              string     `json:"schema"`
-	Queries             string     `json:"queries"`
+	Queries             Paths      `json:"queries"`
 	EmitInterface       bool       `json:"emit_interface"`
+	QuerierPath         string     `json:"querier_path"`
+	QuerierImport       string     `json:"querier_import"`
 	EmitJSONTags        bool       `json:"emit_json_tags"`
+	EmitDBTags          bool       `json:"emit_db_tags"`
+	JSONTagsCaseStyle   string     `json:"json_tags_case_style"`
 	EmitPreparedQueries bool       `json:"emit_prepared_queries"`
+	EmitStmtAccessors   bool       `json:"emit_stmt_accessors"`
+	EmitEnumsFile       bool       `json:"emit_enums_file"`
+	EmitEnumJSON        bool       `json:"emit_enum_json"`
+	EmitEnumValid       bool       `json:"emit_enum_valid"`
+	EmitGoInt           bool       `json:"emit_go_int"`
+	EmitPing            bool       `json:"emit_ping"`
+	EmitExec            bool       `json:"emit_exec"`
+	EmitStringer        bool       `json:"emit_stringer"`
+	EmitDeepCopy        bool       `json:"emit_deep_copy"`
+	EmitMethodExamples  bool       `json:"emit_method_examples"`
+	EmitEmptySlices     bool       `json:"emit_empty_slices"`
+	EmitErrNotFound     bool       `json:"emit_err_not_found"`
+	EmitResultPointers  bool       `json:"emit_result_pointers"`
+	EmitZeroOnNoRows    bool       `json:"emit_zero_on_no_rows"`
+	EmitErrClassifier   bool       `json:"emit_err_classifier"`
+	EmitMock            bool       `json:"emit_mock"`
+	EmitSingleFile      bool       `json:"emit_single_file"`
+	EmitUnexported      bool       `json:"emit_unexported"`
+	EmitQueriesFile     bool       `json:"emit_queries_file"`
+	EmitQueryHook       bool       `json:"emit_query_hook"`
+	EmitStore           bool       `json:"emit_store"`
+	EmitCRUD            bool       `json:"emit_crud"`
+	OmitUnusedStructs   bool       `json:"omit_unused_structs"`
+	EmitCheckConstants  bool       `json:"emit_check_constants"`
+	EmitCheckValidators bool       `json:"emit_check_validators"`
+	StrictArrayTypes    bool       `json:"strict_array_types"`
+	StrictTypes         bool       `json:"strict_types"`
+	FallbackGoType      string     `json:"fallback_go_type"`
+	ReceiverName        string     `json:"receiver_name"`
+	ConstructorName     string     `json:"constructor_name"`
+	ParamsStructSuffix  string     `json:"params_struct_suffix"`
+	RowStructSuffix     string     `json:"row_struct_suffix"`
+	ExcludeTables       []string   `json:"exclude_tables"`
+	EmitNullTypes       bool       `json:"emit_null_types"`
+	EmitNullableArrays  bool       `json:"emit_nullable_arrays"`
+	SearchPath          []string   `json:"search_path"`
+	Header              string     `json:"header"`
+	BuildTags           string     `json:"build_tags"`
+	DefaultQueryTimeout string     `json:"default_query_timeout"`
+	QueryParameterLimit int        `json:"query_parameter_limit"`
 	Overrides           []Override `json:"overrides"`
 }
 
+// Paths is a list of files, directories or glob patterns. In sqlc.json it
+// may be written as a single string or as a list of strings.
+type Paths []string
+
+func (p *Paths) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*p = Paths{path}
+		return nil
+	}
+	var paths []string
+	if err := json.Unmarshal(data, &paths); err != nil {
+		return err
+	}
+	*p = paths
+	return nil
+}
+
 type Override struct {
 	// name of the golang type to use, e.g. `github.com/segmentio/ksuid.KSUID`
 	GoType string `json:"go_type"`
@@ -67,6 +132,26 @@ type Override struct {
 	// fully qualified name of the column, e.g. `accounts.id`
 	Column string `json:"column"`
 
+	// fully qualified name of the table a `postgres_type` override is limited to, e.g. `events`
+	Table string `json:"table"`
+
+	// True if the override applies to every primary key column, optionally
+	// limited to columns of `postgres_type`
+	PrimaryKey bool `json:"primary_key"`
+
+	// name of the Go struct field to use for the column, e.g. `Identifier`
+	GoFieldName string `json:"go_field_name"`
+
+	// extra struct tags for the column's field, e.g. `{"validate": "required"}`
+	GoStructTags map[string]string `json:"go_struct_tags"`
+
+	// forces the column to be nullable (true) or not null (false), regardless
+	// of the nullability sqlc infers
+	Nullable *bool `json:"nullable"`
+
+	// True if values of GoType are stored as JSON, and scanned with json.Unmarshal
+	JSON bool `json:"json"`
+
 	columnName  string
 	table       pg.FQN
 	goTypeName  string
@@ -79,8 +164,35 @@ func (o *Override) Parse() error {
 	switch {
 	case o.Column != "" && o.PostgresType != "":
 		return fmt.Errorf("Override specifying both `column` (%q) and `postgres_type` (%q) is not valid.", o.Column, o.PostgresType)
-	case o.Column == "" && o.PostgresType == "":
+	case o.Column != "" && o.PrimaryKey:
+		return fmt.Errorf("Override specifying both `column` (%q) and `primary_key` is not valid.", o.Column)
+	case o.Column == "" && o.PostgresType == "" && !o.PrimaryKey:
 		return fmt.Errorf("Override must specify one of either `column` or `postgres_type`")
+	case o.GoFieldName != "" && o.Column == "":
+		return fmt.Errorf("Override specifying `go_field_name` (%q) must also specify `column`", o.GoFieldName)
+	case len(o.GoStructTags) > 0 && o.Column == "":
+		return fmt.Errorf("Override specifying `go_struct_tags` must also specify `column`")
+	case o.Nullable != nil && o.Column == "":
+		return fmt.Errorf("Override specifying `nullable` must also specify `column`")
+	case o.JSON && o.GoType == "":
+		return fmt.Errorf("Override specifying `json` must also specify `go_type`")
+	case o.Table != "" && o.PostgresType == "":
+		return fmt.Errorf("Override specifying `table` (%q) must also specify `postgres_type`", o.Table)
+	}
+
+	// validate Table
+	if o.Table != "" {
+		tableParts := strings.Split(o.Table, ".")
+		switch len(tableParts) {
+		case 1:
+			o.table = pg.FQN{Schema: "public", Rel: tableParts[0]}
+		case 2:
+			o.table = pg.FQN{Schema: tableParts[0], Rel: tableParts[1]}
+		case 3:
+			o.table = pg.FQN{Catalog: tableParts[0], Schema: tableParts[1], Rel: tableParts[2]}
+		default:
+			return fmt.Errorf("Override `table` specifier %q is not the proper format, expected '[catalog.][schema.]tablename'", o.Table)
+		}
 	}
 
 	// validate Column
@@ -101,6 +213,12 @@ func (o *Override) Parse() error {
 		}
 	}
 
+	// a column override may only rename or tag the field, or change its
+	// nullability, leaving the type alone
+	if o.GoType == "" && (o.GoFieldName != "" || len(o.GoStructTags) > 0 || o.Nullable != nil) {
+		return nil
+	}
+
 	// validate GoType
 	lastDot := strings.LastIndex(o.GoType, ".")
 	lastSlash := strings.LastIndex(o.GoType, "/")
@@ -129,21 +247,11 @@ func (o *Override) Parse() error {
 		if lastDot == -1 {
 			return fmt.Errorf("Package override `go_type` specifier %q is not the proper format, expected 'package.type', e.g. 'github.com/segmentio/ksuid.KSUID'", o.GoType)
 		}
-		if lastSlash == -1 {
+		if lastSlash == -1 || lastDot < lastSlash {
 			return fmt.Errorf("Package override `go_type` specifier %q is not the proper format, expected 'package.type', e.g. 'github.com/segmentio/ksuid.KSUID'", o.GoType)
 		}
-		typename = o.GoType[lastSlash+1:]
-		if strings.HasPrefix(typename, "go-") {
-			// a package name beginning with "go-" will give syntax errors in
-			// generated code. We should do the right thing and get the actual
-			// import name, but in lieu of that, stripping the leading "go-" may get
-			// us what we want.
-			typename = typename[len("go-"):]
-		}
-		if strings.HasSuffix(typename, "-go") {
-			typename = typename[:len(typename)-len("-go")]
-		}
 		o.goPackage = o.GoType[:lastDot]
+		typename = importName(o.goPackage) + o.GoType[lastDot:]
 	}
 	o.goTypeName = typename
 	isPointer := o.GoType[0] == '*'
@@ -155,11 +263,48 @@ func (o *Override) Parse() error {
 	return nil
 }
 
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName guesses the name a package is referred to by from its import
+// path. We should do the right thing and read the package clause, but the
+// conventions below cover most packages.
+func importName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	// example.com/pkg/v2 is package pkg
+	if majorVersion.MatchString(name) && len(parts) > 1 {
+		name = parts[len(parts)-2]
+	}
+	// gopkg.in/yaml.v2 is package yaml
+	if i := strings.Index(name, ".v"); i > 0 && majorVersion.MatchString(name[i+1:]) {
+		name = name[:i]
+	}
+	// a package name beginning with "go-", "go." or ending with "-go" would
+	// give syntax errors in generated code
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimPrefix(name, "go.")
+	name = strings.TrimSuffix(name, "-go")
+	return name
+}
+
 var ErrMissingVersion = errors.New("no version number")
 var ErrUnknownVersion = errors.New("invalid version number")
 var ErrNoPackages = errors.New("no packages")
 var ErrNoPackageName = errors.New("missing package name")
 var ErrNoPackagePath = errors.New("missing package path")
+var ErrUnknownJSONTagsCaseStyle = errors.New("invalid json_tags_case_style")
+var ErrInvalidQueryTimeout = errors.New("invalid default_query_timeout")
+var ErrInvalidQueryParameterLimit = errors.New("invalid query_parameter_limit")
+var ErrInvalidReceiverName = errors.New("invalid receiver_name")
+var ErrInvalidConstructorName = errors.New("invalid constructor_name")
+var ErrInvalidQuerierPath = errors.New("invalid querier_path")
+var ErrMissingQuerierImport = errors.New("missing querier_import")
+var ErrInvalidParamsStructSuffix = errors.New("invalid params_struct_suffix")
+var ErrInvalidRowStructSuffix = errors.New("invalid row_struct_suffix")
+var ErrInvalidFallbackGoType = errors.New("invalid fallback_go_type")
+var ErrUnsupportedMySQLOverride = errors.New("go_field_name and nullable overrides are not supported by the mysql engine")
+var ErrUnsupportedMySQLCRUD = errors.New("emit_crud is not supported by the mysql engine")
+var ErrConflictingNoRowsOptions = errors.New("only one of emit_result_pointers, emit_zero_on_no_rows and emit_err_not_found may be set")
 
 func ParseConfig(rd io.Reader) (GenerateSettings, error) {
 	dec := json.NewDecoder(rd)
@@ -197,12 +342,228 @@ func ParseConfig(rd io.Reader) (GenerateSettings, error) {
 		if config.Packages[j].Engine == "" {
 			config.Packages[j].Engine = EnginePostgreSQL
 		}
+		if config.Packages[j].Engine == EngineMySQL {
+			for _, o := range append(config.Overrides, config.Packages[j].Overrides...) {
+				if o.GoFieldName != "" || o.Nullable != nil {
+					return config, ErrUnsupportedMySQLOverride
+				}
+			}
+			if config.Packages[j].EmitCRUD {
+				return config, ErrUnsupportedMySQLCRUD
+			}
+		}
+		if config.Packages[j].conflictingNoRowsOptions() {
+			return config, ErrConflictingNoRowsOptions
+		}
+		if len(config.Packages[j].SearchPath) == 0 {
+			config.Packages[j].SearchPath = []string{"public"}
+		}
+		switch config.Packages[j].JSONTagsCaseStyle {
+		case "", "camel", "pascal", "snake":
+		default:
+			return config, ErrUnknownJSONTagsCaseStyle
+		}
+		if _, err := config.Packages[j].queryTimeout(); err != nil {
+			return config, ErrInvalidQueryTimeout
+		}
+		if config.Packages[j].QueryParameterLimit < 0 {
+			return config, ErrInvalidQueryParameterLimit
+		}
+		if name := config.Packages[j].ReceiverName; name != "" && !validReceiverName(name) {
+			return config, ErrInvalidReceiverName
+		}
+		if name := config.Packages[j].ConstructorName; name != "" && !validConstructorName(name) {
+			return config, ErrInvalidConstructorName
+		}
+		if path := config.Packages[j].QuerierPath; path != "" {
+			if filepath.IsAbs(path) || !token.IsIdentifier(filepath.Base(path)) {
+				return config, ErrInvalidQuerierPath
+			}
+			if config.Packages[j].QuerierImport == "" {
+				return config, ErrMissingQuerierImport
+			}
+		}
+		if _, err := config.Packages[j].fallbackOverride(); err != nil {
+			return config, ErrInvalidFallbackGoType
+		}
+		if !validStructSuffix(config.Packages[j].ParamsStructSuffix) {
+			return config, ErrInvalidParamsStructSuffix
+		}
+		if !validStructSuffix(config.Packages[j].RowStructSuffix) || config.Packages[j].paramsStructSuffix() == config.Packages[j].rowStructSuffix() {
+			return config, ErrInvalidRowStructSuffix
+		}
 	}
 	err := config.PopulatePkgMap()
 
 	return config, err
 }
 
+// queryTimeout parses the default_query_timeout duration. A zero duration
+// means generated methods use the caller's context as is.
+func (p PackageSettings) queryTimeout() (time.Duration, error) {
+	if p.DefaultQueryTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(p.DefaultQueryTimeout)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("non-positive duration %s", d)
+	}
+	return d, nil
+}
+
+// ParamsStructLimit is the number of parameters at which a query takes a
+// Params struct instead of positional arguments. Defaults to 2.
+func (p PackageSettings) ParamsStructLimit() int {
+	if p.QueryParameterLimit == 0 {
+		return 2
+	}
+	return p.QueryParameterLimit
+}
+
+func (p PackageSettings) receiverName() string {
+	if p.ReceiverName == "" {
+		return "q"
+	}
+	return p.ReceiverName
+}
+
+func (p PackageSettings) constructorName() string {
+	if p.ConstructorName == "" {
+		return "New"
+	}
+	return p.ConstructorName
+}
+
+// querierFile is the name, relative to path, of the file holding the Querier
+// interface when querier_path moves it to its own package
+func (p PackageSettings) querierFile() string {
+	if p.QuerierPath == "" || !p.EmitInterface {
+		return ""
+	}
+	return filepath.ToSlash(filepath.Join(p.QuerierPath, "querier.go"))
+}
+
+// querierPackage is the package clause of the querier file
+func (p PackageSettings) querierPackage() string {
+	if p.querierFile() == "" {
+		return ""
+	}
+	return filepath.Base(p.QuerierPath)
+}
+
+func (p PackageSettings) paramsStructSuffix() string {
+	if p.ParamsStructSuffix == "" {
+		return "Params"
+	}
+	return p.ParamsStructSuffix
+}
+
+func (p PackageSettings) rowStructSuffix() string {
+	if p.RowStructSuffix == "" {
+		return "Row"
+	}
+	return p.RowStructSuffix
+}
+
+// fallbackOverride parses fallback_go_type like the `go_type` of an override
+func (p PackageSettings) fallbackOverride() (Override, error) {
+	o := Override{PostgresType: "any", GoType: p.FallbackGoType}
+	if p.FallbackGoType == "" {
+		return o, nil
+	}
+	err := o.Parse()
+	return o, err
+}
+
+// fallbackType returns the Go type, and the package it's imported from, of
+// values whose type has no Go mapping. Defaults to interface{}.
+func (p PackageSettings) fallbackType() (string, string) {
+	o, err := p.fallbackOverride()
+	if err != nil || o.goTypeName == "" {
+		return "interface{}", ""
+	}
+	if o.goBasicType {
+		return o.goTypeName, ""
+	}
+	return o.goTypeName, o.goPackage
+}
+
+// ParamsStructName returns the name of the struct holding the parameters of
+// the query method
+func (p PackageSettings) ParamsStructName(method string) string {
+	return method + p.paramsStructSuffix()
+}
+
+// RowStructName returns the name of the struct holding a row returned by the
+// query method
+func (p PackageSettings) RowStructName(method string) string {
+	return method + p.rowStructSuffix()
+}
+
+// ExcludesTable reports whether no struct should be generated for the table.
+// Tables in the public schema, or in engines without schemas, may be listed
+// without a schema.
+func (p PackageSettings) ExcludesTable(schema, table string) bool {
+	for _, name := range p.ExcludeTables {
+		if name == schema+"."+table || (name == table && (schema == "" || schema == "public")) {
+			return true
+		}
+	}
+	return false
+}
+
+// validStructSuffix reports whether suffix can follow a method name to form
+// an identifier. An empty suffix selects the default.
+func validStructSuffix(suffix string) bool {
+	return suffix == "" || token.IsIdentifier("X"+suffix)
+}
+
+// conflictingNoRowsOptions reports whether more than one of the options that
+// decide what a :one method returns when no row matches is set
+func (p PackageSettings) conflictingNoRowsOptions() bool {
+	n := 0
+	for _, set := range []bool{p.EmitResultPointers, p.EmitZeroOnNoRows, p.EmitErrNotFound} {
+		if set {
+			n++
+		}
+	}
+	return n > 1
+}
+
+// validReceiverName reports whether name is an identifier that doesn't
+// shadow the locals, imported packages or package-level helpers used in
+// generated methods
+func validReceiverName(name string) bool {
+	if !token.IsIdentifier(name) {
+		return false
+	}
+	switch name {
+	case "_", "a", "arg", "args", "batch", "cancel", "cerr", "ctx", "err", "i", "items", "n", "one", "query", "result", "row", "rows", "stmt", "tx":
+		return false
+	case "context", "driver", "errors", "fmt", "json", "net", "pq", "reflect", "sql", "strconv", "strings", "time", "uuid":
+		return false
+	case "ErrNotFound", "QueryHook", "defaultQueryTimeout", "jsonValue", "nullArray", "ping":
+		return false
+	}
+	return true
+}
+
+// validConstructorName reports whether name is an identifier that doesn't
+// clash with the other declarations in db.go
+func validConstructorName(name string) bool {
+	if !token.IsIdentifier(name) {
+		return false
+	}
+	switch name {
+	case "_", "DBTX", "Prepare", "Queries":
+		return false
+	}
+	return true
+}
+
 func (s *GenerateSettings) PopulatePkgMap() error {
 	packageMap := make(map[string]PackageSettings)
 
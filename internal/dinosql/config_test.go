@@ -22,6 +22,192 @@ const unknownFields = `{
   "foo": "bar"
 }`
 
+const unknownJSONTagsCaseStyle = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "json_tags_case_style": "kebab"
+    }
+  ]
+}`
+
+const invalidQueryTimeout = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "default_query_timeout": "soon"
+    }
+  ]
+}`
+
+const invalidQueryParameterLimit = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "query_parameter_limit": -1
+    }
+  ]
+}`
+
+const mysqlFieldNameOverride = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "engine": "mysql",
+      "overrides": [
+        {
+          "column": "authors.id",
+          "go_field_name": "Identifier"
+        }
+      ]
+    }
+  ]
+}`
+
+const mysqlNullableOverride = `{
+  "version": "1",
+  "overrides": [
+    {
+      "column": "authors.bio",
+      "nullable": false
+    }
+  ],
+  "packages": [
+    {
+      "path": "db",
+      "engine": "mysql"
+    }
+  ]
+}`
+
+const mysqlCRUD = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "engine": "mysql",
+      "emit_crud": true
+    }
+  ]
+}`
+
+const pointersAndZeroOnNoRows = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "emit_result_pointers": true,
+      "emit_zero_on_no_rows": true
+    }
+  ]
+}`
+
+const errNotFoundAndZeroOnNoRows = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "emit_err_not_found": true,
+      "emit_zero_on_no_rows": true
+    }
+  ]
+}`
+
+const invalidReceiverName = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "receiver_name": "ctx"
+    }
+  ]
+}`
+
+const receiverNameShadowsArg = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "receiver_name": "arg"
+    }
+  ]
+}`
+
+const receiverNameShadowsPackage = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "receiver_name": "sql"
+    }
+  ]
+}`
+
+const invalidConstructorName = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "constructor_name": "Queries"
+    }
+  ]
+}`
+
+const invalidQuerierPath = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "querier_path": "/ports",
+      "querier_import": "example.com/ports"
+    }
+  ]
+}`
+
+const missingQuerierImport = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "querier_path": "../ports"
+    }
+  ]
+}`
+
+const invalidParamsStructSuffix = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "params_struct_suffix": "-params"
+    }
+  ]
+}`
+
+const duplicateStructSuffix = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "params_struct_suffix": "Row"
+    }
+  ]
+}`
+
+const invalidFallbackGoType = `{
+  "version": "1",
+  "packages": [
+    {
+      "path": "db",
+      "fallback_go_type": "example.com/pgtype"
+    }
+  ]
+}`
+
 func TestBadConfigs(t *testing.T) {
 	for _, test := range []struct {
 		name string
@@ -48,6 +234,91 @@ func TestBadConfigs(t *testing.T) {
 			"json: unknown field \"foo\"",
 			unknownFields,
 		},
+		{
+			"unknown json tags case style",
+			"invalid json_tags_case_style",
+			unknownJSONTagsCaseStyle,
+		},
+		{
+			"invalid query timeout",
+			"invalid default_query_timeout",
+			invalidQueryTimeout,
+		},
+		{
+			"invalid query parameter limit",
+			"invalid query_parameter_limit",
+			invalidQueryParameterLimit,
+		},
+		{
+			"mysql field name override",
+			"go_field_name and nullable overrides are not supported by the mysql engine",
+			mysqlFieldNameOverride,
+		},
+		{
+			"mysql nullable override",
+			"go_field_name and nullable overrides are not supported by the mysql engine",
+			mysqlNullableOverride,
+		},
+		{
+			"mysql crud",
+			"emit_crud is not supported by the mysql engine",
+			mysqlCRUD,
+		},
+		{
+			"result pointers and zero on no rows",
+			"only one of emit_result_pointers, emit_zero_on_no_rows and emit_err_not_found may be set",
+			pointersAndZeroOnNoRows,
+		},
+		{
+			"err not found and zero on no rows",
+			"only one of emit_result_pointers, emit_zero_on_no_rows and emit_err_not_found may be set",
+			errNotFoundAndZeroOnNoRows,
+		},
+		{
+			"invalid receiver name",
+			"invalid receiver_name",
+			invalidReceiverName,
+		},
+		{
+			"receiver name shadows arg",
+			"invalid receiver_name",
+			receiverNameShadowsArg,
+		},
+		{
+			"receiver name shadows an imported package",
+			"invalid receiver_name",
+			receiverNameShadowsPackage,
+		},
+		{
+			"invalid constructor name",
+			"invalid constructor_name",
+			invalidConstructorName,
+		},
+		{
+			"invalid querier path",
+			"invalid querier_path",
+			invalidQuerierPath,
+		},
+		{
+			"missing querier import",
+			"missing querier_import",
+			missingQuerierImport,
+		},
+		{
+			"invalid params struct suffix",
+			"invalid params_struct_suffix",
+			invalidParamsStructSuffix,
+		},
+		{
+			"duplicate struct suffix",
+			"invalid row_struct_suffix",
+			duplicateStructSuffix,
+		},
+		{
+			"invalid fallback go type",
+			"invalid fallback_go_type",
+			invalidFallbackGoType,
+		},
 	} {
 		tt := test
 		t.Run(tt.name, func(t *testing.T) {
@@ -98,6 +369,42 @@ func TestTypeOverrides(t *testing.T) {
 			"string",
 			true,
 		},
+		{
+			Override{
+				PostgresType: "uuid",
+				GoType:       "example.com/pkg/v2.ID",
+			},
+			"example.com/pkg/v2",
+			"pkg.ID",
+			false,
+		},
+		{
+			Override{
+				PostgresType: "text",
+				GoType:       "golang.org/x/oauth2.Token",
+			},
+			"golang.org/x/oauth2",
+			"oauth2.Token",
+			false,
+		},
+		{
+			Override{
+				PostgresType: "jsonb",
+				GoType:       "gopkg.in/yaml.v2.MapSlice",
+			},
+			"gopkg.in/yaml.v2",
+			"yaml.MapSlice",
+			false,
+		},
+		{
+			Override{
+				PostgresType: "uuid",
+				GoType:       "github.com/satori/go.uuid.UUID",
+			},
+			"github.com/satori/go.uuid",
+			"uuid.UUID",
+			false,
+		},
 	} {
 		tt := test
 		t.Run(tt.override.GoType, func(t *testing.T) {
@@ -133,6 +440,58 @@ func TestTypeOverrides(t *testing.T) {
 			},
 			"Package override `go_type` specifier \"untyped rune\" is not a Go basic type e.g. 'string'",
 		},
+		{
+			Override{
+				PostgresType: "uuid",
+				GoType:       "example.com/uuid",
+			},
+			"Package override `go_type` specifier \"example.com/uuid\" is not the proper format, expected 'package.type', e.g. 'github.com/segmentio/ksuid.KSUID'",
+		},
+		{
+			Override{
+				Table:  "events",
+				GoType: "string",
+			},
+			"Override must specify one of either `column` or `postgres_type`",
+		},
+		{
+			Override{
+				Column: "events.created_at",
+				Table:  "events",
+				GoType: "string",
+			},
+			"Override specifying `table` (\"events\") must also specify `postgres_type`",
+		},
+		{
+			Override{
+				Column:     "events.id",
+				PrimaryKey: true,
+				GoType:     "string",
+			},
+			"Override specifying both `column` (\"events.id\") and `primary_key` is not valid.",
+		},
+		{
+			Override{
+				Column:      "events.payload",
+				GoFieldName: "Payload",
+				JSON:        true,
+			},
+			"Override specifying `json` must also specify `go_type`",
+		},
+		{
+			Override{
+				PostgresType: "text",
+				GoStructTags: map[string]string{"validate": "required"},
+			},
+			"Override specifying `go_struct_tags` must also specify `column`",
+		},
+		{
+			Override{
+				PostgresType: "text",
+				Nullable:     new(bool),
+			},
+			"Override specifying `nullable` must also specify `column`",
+		},
 	} {
 		tt := test
 		t.Run(tt.override.GoType, func(t *testing.T) {
@@ -146,3 +505,24 @@ func TestTypeOverrides(t *testing.T) {
 		})
 	}
 }
+
+func TestQueriesPaths(t *testing.T) {
+	for _, tc := range []struct {
+		json  string
+		paths Paths
+	}{
+		{`"queries"`, Paths{"queries"}},
+		{`["queries/authors", "queries/books/*.sql"]`, Paths{"queries/authors", "queries/books/*.sql"}},
+	} {
+		test := tc
+		t.Run(test.json, func(t *testing.T) {
+			conf, err := ParseConfig(strings.NewReader(`{"version": "1", "packages": [{"path": "db", "queries": ` + test.json + `}]}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.paths, conf.Packages[0].Queries); diff != "" {
+				t.Errorf("queries mismatch:\n%s", diff)
+			}
+		})
+	}
+}
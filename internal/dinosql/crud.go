@@ -0,0 +1,136 @@
+package dinosql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	core "github.com/kyleconroy/sqlc/internal/pg"
+
+	"github.com/jinzhu/inflection"
+	pg "github.com/lfittl/pg_query_go"
+)
+
+// crudFilename is the source name of the generated CRUD queries, so their
+// methods are written to crud.sql.go
+const crudFilename = "crud.sql"
+
+// crudQueries returns Get, List, Create, Update and Delete queries for every
+// table with a single column primary key. Queries whose names are in skip
+// are left out, so a hand-written query replaces the generated one.
+func crudQueries(c core.Catalog, settings GenerateSettings, pkg PackageSettings, skip map[string]struct{}) ([]*Query, error) {
+	source := crudSource(c, settings, pkg)
+	if source == "" {
+		return nil, nil
+	}
+	tree, err := pg.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	var qs []*Query
+	for _, stmt := range tree.Statements {
+		q, err := parseQuery(c, stmt, source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", crudFilename, err)
+		}
+		if _, exists := skip[q.Name]; exists {
+			continue
+		}
+		q.Filename = crudFilename
+		qs = append(qs, q)
+	}
+	return qs, nil
+}
+
+// crudSource writes the SQL for the CRUD queries of each table
+func crudSource(c core.Catalog, settings GenerateSettings, pkg PackageSettings) string {
+	var b strings.Builder
+	for _, name := range schemaNames(c) {
+		if name == "pg_catalog" {
+			continue
+		}
+		schema := c.Schemas[name]
+		tables := make([]string, 0, len(schema.Tables))
+		for rel := range schema.Tables {
+			tables = append(tables, rel)
+		}
+		sort.Strings(tables)
+		for _, rel := range tables {
+			if pkg.ExcludesTable(name, rel) {
+				continue
+			}
+			writeCRUD(&b, settings, name, schema.Tables[rel])
+		}
+	}
+	return b.String()
+}
+
+func writeCRUD(b *strings.Builder, settings GenerateSettings, schema string, table core.Table) {
+	var pk *core.Column
+	var cols, inserts []core.Column
+	for i := range table.Columns {
+		col := table.Columns[i]
+		if col.PrimaryKey {
+			if pk != nil {
+				// Composite keys have no single identifier to look up by
+				return
+			}
+			pk = &table.Columns[i]
+		} else {
+			cols = append(cols, col)
+		}
+		if !isSerial(col.DataType) && !col.HasDefault {
+			inserts = append(inserts, col)
+		}
+	}
+	if pk == nil {
+		return
+	}
+
+	tableName := table.Name
+	rel := quoteIdent(table.Name)
+	if schema != "public" {
+		tableName = schema + "_" + table.Name
+		rel = quoteIdent(schema) + "." + rel
+	}
+	singular := inflection.Singular(StructName(tableName, settings))
+	plural := inflection.Plural(singular)
+	key := quoteIdent(pk.Name)
+
+	fmt.Fprintf(b, "-- name: Get%s :one\nSELECT * FROM %s WHERE %s = $1;\n\n", singular, rel, key)
+	fmt.Fprintf(b, "-- name: List%s :many\nSELECT * FROM %s ORDER BY %s;\n\n", plural, rel, key)
+
+	if len(inserts) == 0 {
+		fmt.Fprintf(b, "-- name: Create%s :one\nINSERT INTO %s DEFAULT VALUES RETURNING *;\n\n", singular, rel)
+	} else {
+		names := make([]string, len(inserts))
+		values := make([]string, len(inserts))
+		for i, col := range inserts {
+			names[i] = quoteIdent(col.Name)
+			values[i] = fmt.Sprintf("$%d", i+1)
+		}
+		fmt.Fprintf(b, "-- name: Create%s :one\nINSERT INTO %s (%s) VALUES (%s) RETURNING *;\n\n",
+			singular, rel, strings.Join(names, ", "), strings.Join(values, ", "))
+	}
+
+	if len(cols) > 0 {
+		sets := make([]string, len(cols))
+		for i, col := range cols {
+			sets[i] = fmt.Sprintf("%s = $%d", quoteIdent(col.Name), i+2)
+		}
+		fmt.Fprintf(b, "-- name: Update%s :one\nUPDATE %s SET %s WHERE %s = $1 RETURNING *;\n\n",
+			singular, rel, strings.Join(sets, ", "), key)
+	}
+
+	fmt.Fprintf(b, "-- name: Delete%s :exec\nDELETE FROM %s WHERE %s = $1;\n\n", singular, rel, key)
+}
+
+func isSerial(dataType string) bool {
+	switch dataType {
+	case "serial", "pg_catalog.serial4",
+		"bigserial", "pg_catalog.serial8",
+		"smallserial", "pg_catalog.serial2":
+		return true
+	}
+	return false
+}
@@ -5,12 +5,17 @@ import (
 	"bytes"
 	"fmt"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
 	"log"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
 
 	core "github.com/kyleconroy/sqlc/internal/pg"
@@ -32,11 +37,31 @@ type GoEnum struct {
 	Constants []GoConstant
 }
 
+// GoNullType is a generated wrapper around one of the sql.Null* types that
+// marshals to JSON as the bare value or null
+type GoNullType struct {
+	Name  string // e.g. NullInt32
+	Field string // e.g. Int32
+}
+
+var goNullTypes = []GoNullType{
+	{Name: "NullBool", Field: "Bool"},
+	{Name: "NullFloat64", Field: "Float64"},
+	{Name: "NullInt32", Field: "Int32"},
+	{Name: "NullInt64", Field: "Int64"},
+	{Name: "NullString", Field: "String"},
+	{Name: "NullTime", Field: "Time"},
+}
+
 type GoField struct {
 	Name    string
 	Type    string
 	Tags    map[string]string
 	Comment string
+
+	// JSON fields are scanned with json.Unmarshal and passed as arguments
+	// with json.Marshal
+	JSON bool
 }
 
 func (gf GoField) Tag() string {
@@ -48,7 +73,7 @@ func (gf GoField) Tag() string {
 		return ""
 	}
 	sort.Strings(tags)
-	return strings.Join(tags, ",")
+	return strings.Join(tags, " ")
 }
 
 type GoStruct struct {
@@ -56,6 +81,63 @@ type GoStruct struct {
 	Name    string
 	Fields  []GoField
 	Comment string
+
+	// Composite structs implement sql.Scanner and driver.Valuer using the
+	// composite text format, e.g. (1,hello)
+	Composite bool
+}
+
+// StringerFields are the fields printed by a generated String method. Fields
+// hidden from JSON with a "-" tag are left out.
+func (gs GoStruct) StringerFields() []GoField {
+	var fields []GoField
+	for _, f := range gs.Fields {
+		if f.Tags["json:"] == "-" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// StringerFormat is the quoted fmt.Sprintf format of a generated String
+// method, e.g. "Author{ID:%v Name:%v}"
+func (gs GoStruct) StringerFormat() string {
+	var pairs []string
+	for _, f := range gs.StringerFields() {
+		pairs = append(pairs, f.Name+":%v")
+	}
+	return strconv.Quote(gs.Name + "{" + strings.Join(pairs, " ") + "}")
+}
+
+// DeepCopyStmts are the statements in a generated DeepCopy method that copy
+// the slice and pointer fields of v into c. Other fields, including those of
+// overridden types, are copied by value.
+func (gs GoStruct) DeepCopyStmts() []string {
+	var stmts []string
+	for _, f := range gs.Fields {
+		switch {
+		case strings.HasPrefix(f.Type, "*[]"):
+			stmts = append(stmts, fmt.Sprintf("if v.%[1]s != nil {\nx := make(%[2]s, len(*v.%[1]s))\ncopy(x, *v.%[1]s)\nc.%[1]s = &x\n}", f.Name, f.Type[1:]))
+		case strings.HasPrefix(f.Type, "*"):
+			stmts = append(stmts, fmt.Sprintf("if v.%[1]s != nil {\nx := *v.%[1]s\nc.%[1]s = &x\n}", f.Name))
+		case isSliceType(f.Type) && strings.HasPrefix(f.Type, "[]") && isSliceType(f.Type[2:]):
+			stmts = append(stmts, fmt.Sprintf("if v.%[1]s != nil {\nc.%[1]s = make(%[2]s, len(v.%[1]s))\nfor i := range v.%[1]s {\nc.%[1]s[i] = append(%[3]s(nil), v.%[1]s[i]...)\n}\n}", f.Name, f.Type, f.Type[2:]))
+		case isSliceType(f.Type):
+			stmts = append(stmts, fmt.Sprintf("if v.%[1]s != nil {\nc.%[1]s = make(%[2]s, len(v.%[1]s))\ncopy(c.%[1]s, v.%[1]s)\n}", f.Name, f.Type))
+		}
+	}
+	return stmts
+}
+
+// isSliceType reports whether typ is a slice, including the generated byte
+// slice types
+func isSliceType(typ string) bool {
+	switch typ {
+	case "json.RawMessage", "net.IP":
+		return true
+	}
+	return strings.HasPrefix(typ, "[]")
 }
 
 type GoQueryValue struct {
@@ -63,6 +145,17 @@ type GoQueryValue struct {
 	Name   string
 	Struct *GoStruct
 	Typ    string
+
+	// Positional values pass each field of Struct as its own argument
+	// instead of wrapping them in a struct
+	Positional bool
+
+	// JSON values are scanned with json.Unmarshal and passed as arguments
+	// with json.Marshal
+	JSON bool
+
+	// Slice values take a slice of Struct, one element per VALUES row
+	Slice bool
 }
 
 func (v GoQueryValue) EmitStruct() bool {
@@ -81,6 +174,16 @@ func (v GoQueryValue) Pair() string {
 	if v.isEmpty() {
 		return ""
 	}
+	if v.Positional {
+		var out []string
+		for _, f := range v.Struct.Fields {
+			out = append(out, f.Name+" "+f.Type)
+		}
+		return strings.Join(out, ", ")
+	}
+	if v.Slice {
+		return v.Name + " []" + v.Type()
+	}
 	return v.Name + " " + v.Type()
 }
 
@@ -100,17 +203,29 @@ func (v GoQueryValue) Params() string {
 	}
 	var out []string
 	if v.Struct == nil {
-		if strings.HasPrefix(v.Typ, "[]") && v.Typ != "[]byte" {
+		if strings.HasPrefix(v.Typ, "*[]") {
+			out = append(out, "nullArray{"+v.Name+"}")
+		} else if strings.HasPrefix(v.Typ, "[]") && v.Typ != "[]byte" {
 			out = append(out, "pq.Array("+v.Name+")")
+		} else if v.JSON {
+			out = append(out, "jsonValue{"+v.Name+"}")
 		} else {
 			out = append(out, v.Name)
 		}
 	} else {
 		for _, f := range v.Struct.Fields {
-			if strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" {
-				out = append(out, "pq.Array("+v.Name+"."+f.Name+")")
+			name := v.Name + "." + f.Name
+			if v.Positional {
+				name = f.Name
+			}
+			if strings.HasPrefix(f.Type, "*[]") {
+				out = append(out, "nullArray{"+name+"}")
+			} else if strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" {
+				out = append(out, "pq.Array("+name+")")
+			} else if f.JSON {
+				out = append(out, "jsonValue{"+name+"}")
 			} else {
-				out = append(out, v.Name+"."+f.Name)
+				out = append(out, name)
 			}
 		}
 	}
@@ -121,18 +236,42 @@ func (v GoQueryValue) Params() string {
 	return "\n" + strings.Join(out, ",\n")
 }
 
+// Names returns the parameter names from Pair, for passing the arguments on
+// unchanged
+func (v GoQueryValue) Names() string {
+	if v.isEmpty() {
+		return ""
+	}
+	if v.Positional {
+		var out []string
+		for _, f := range v.Struct.Fields {
+			out = append(out, f.Name)
+		}
+		return strings.Join(out, ", ")
+	}
+	return v.Name
+}
+
 func (v GoQueryValue) Scan() string {
 	var out []string
 	if v.Struct == nil {
-		if strings.HasPrefix(v.Typ, "[]") && v.Typ != "[]byte" {
+		if strings.HasPrefix(v.Typ, "*[]") {
+			out = append(out, "nullArray{&"+v.Name+"}")
+		} else if strings.HasPrefix(v.Typ, "[]") && v.Typ != "[]byte" {
 			out = append(out, "pq.Array(&"+v.Name+")")
+		} else if v.JSON {
+			out = append(out, "jsonValue{&"+v.Name+"}")
 		} else {
 			out = append(out, "&"+v.Name)
 		}
 	} else {
 		for _, f := range v.Struct.Fields {
-			if strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" {
+			if strings.HasPrefix(f.Type, "*[]") {
+				out = append(out, "nullArray{&"+v.Name+"."+f.Name+"}")
+			} else if strings.HasPrefix(f.Type, "[]") && f.Type != "[]byte" {
 				out = append(out, "pq.Array(&"+v.Name+"."+f.Name+")")
+			} else if f.JSON {
+				out = append(out, "jsonValue{&"+v.Name+"."+f.Name+"}")
 			} else {
 				out = append(out, "&"+v.Name+"."+f.Name)
 			}
@@ -156,6 +295,94 @@ type GoQuery struct {
 	SourceName   string
 	Ret          GoQueryValue
 	Arg          GoQueryValue
+
+	// Values is set for :execmany queries
+	Values *GoValues
+
+	// RetPointer is set for :one queries returning a struct when
+	// emit_result_pointers is set. No row is returned as nil.
+	RetPointer bool
+}
+
+// OneType is the type returned by a :one method
+func (q GoQuery) OneType() string {
+	if q.RetPointer {
+		return "*" + q.Ret.Type()
+	}
+	return q.Ret.Type()
+}
+
+// Example returns the doc comment lines showing a call of the method, added
+// when emit_method_examples is set
+func (q GoQuery) Example(receiver string) []string {
+	args := "ctx"
+	if names := q.Arg.Names(); names != "" {
+		args += ", " + names
+	}
+	results := "err"
+	switch q.Cmd {
+	case ":one":
+		results = "row, err"
+	case ":many":
+		results = "rows, err"
+	case ":execrows":
+		results = "n, err"
+	}
+	var lines []string
+	if len(q.Comments) > 0 {
+		lines = append(lines, "")
+	}
+	return append(lines, " Example:", "", fmt.Sprintf("\t%s := %s.%s(%s)", results, receiver, q.MethodName, args))
+}
+
+// GoValues splits an :execmany query around its VALUES row, which is
+// repeated at runtime for each element of the argument
+type GoValues struct {
+	Prefix string
+	Suffix string
+
+	// Quoted format string for one row, taking each parameter's number
+	Row string
+
+	// Parameters in each row, and the arguments for element a
+	Width int
+	Args  string
+}
+
+// maxBindParams is the most parameters PostgreSQL and MySQL bind in a single
+// statement
+const maxBindParams = 65535
+
+// BatchSize returns the most rows a single statement can insert without
+// exceeding maxBindParams
+func (v GoValues) BatchSize() int {
+	return maxBindParams / v.Width
+}
+
+// Numbers returns the parameter numbers for the row starting after n
+func (v GoValues) Numbers() string {
+	var out []string
+	for i := 1; i <= v.Width; i++ {
+		out = append(out, fmt.Sprintf("n+%d", i))
+	}
+	return strings.Join(out, ", ")
+}
+
+func goValues(sql string, arg GoQueryValue) *GoValues {
+	start, end, ok := valuesRow(sql)
+	if !ok {
+		// validateExecMany has already rejected the query
+		return nil
+	}
+	row := strings.Replace(sql[start:end+1], "%", "%%", -1)
+	row = paramPattern.ReplaceAllString(row, "$$%[${1}]d")
+	return &GoValues{
+		Prefix: sql[:start],
+		Suffix: sql[end+1:],
+		Row:    strconv.Quote(row),
+		Width:  len(arg.Struct.Fields),
+		Args:   GoQueryValue{Name: "a", Struct: arg.Struct}.Params(),
+	}
 }
 
 type Generateable interface {
@@ -163,12 +390,13 @@ type Generateable interface {
 	PkgName() string
 	GoQueries(settings GenerateSettings) []GoQuery
 	Enums(settings GenerateSettings) []GoEnum
+	CheckEnums(settings GenerateSettings) []GoEnum
 }
 
 func UsesType(r Generateable, typ string, settings GenerateSettings) bool {
 	for _, strct := range r.Structs(settings) {
 		for _, f := range strct.Fields {
-			fType := strings.TrimPrefix(f.Type, "[]")
+			fType := elemType(f.Type)
 			if strings.HasPrefix(fType, typ) {
 				return true
 			}
@@ -177,6 +405,69 @@ func UsesType(r Generateable, typ string, settings GenerateSettings) bool {
 	return false
 }
 
+// elemType strips the slice, or the pointer to a nullable array, from a Go
+// type
+func elemType(typ string) string {
+	if strings.HasPrefix(typ, "*[]") {
+		return typ[3:]
+	}
+	return strings.TrimPrefix(typ, "[]")
+}
+
+func UsesComposites(r Generateable, settings GenerateSettings) bool {
+	for _, strct := range r.Structs(settings) {
+		if strct.Composite {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesJSONValues reports whether any query scans or passes a value with the
+// generated jsonValue helper
+func UsesJSONValues(r Generateable, settings GenerateSettings) bool {
+	for _, q := range r.GoQueries(settings) {
+		for _, v := range []GoQueryValue{q.Arg, q.Ret} {
+			if v.JSON {
+				return true
+			}
+			if v.Struct == nil {
+				continue
+			}
+			for _, f := range v.Struct.Fields {
+				if f.JSON {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// UsesNullArrays reports whether any query scans or passes a nullable array
+// with the generated nullArray helper
+func UsesNullArrays(r Generateable, settings GenerateSettings) bool {
+	for _, q := range r.GoQueries(settings) {
+		for _, v := range []GoQueryValue{q.Arg, q.Ret} {
+			if v.isEmpty() {
+				continue
+			}
+			if v.Struct == nil {
+				if strings.HasPrefix(v.Typ, "*[]") {
+					return true
+				}
+				continue
+			}
+			for _, f := range v.Struct.Fields {
+				if strings.HasPrefix(f.Type, "*[]") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func UsesArrays(r Generateable, settings GenerateSettings) bool {
 	for _, strct := range r.Structs(settings) {
 		for _, f := range strct.Fields {
@@ -192,20 +483,84 @@ func Imports(r Generateable, settings GenerateSettings) func(string) [][]string
 	return func(filename string) [][]string {
 		if filename == "db.go" {
 			imps := []string{"context", "database/sql"}
-			if settings.PackageMap[r.PkgName()].EmitPreparedQueries {
+			if settings.PackageMap[r.PkgName()].EmitPreparedQueries || settings.PackageMap[r.PkgName()].EmitErrNotFound {
 				imps = append(imps, "fmt")
 			}
-			return [][]string{imps}
+			if settings.PackageMap[r.PkgName()].DefaultQueryTimeout != "" {
+				imps = append(imps, "time")
+			}
+			if UsesJSONValues(r, settings) {
+				imps = append(imps, "database/sql/driver", "encoding/json")
+				if !settings.PackageMap[r.PkgName()].EmitPreparedQueries && !settings.PackageMap[r.PkgName()].EmitErrNotFound {
+					imps = append(imps, "fmt")
+				}
+			}
+			var pkgs []string
+			if UsesNullArrays(r, settings) {
+				if !UsesJSONValues(r, settings) {
+					imps = append(imps, "database/sql/driver")
+				}
+				imps = append(imps, "reflect")
+				pkgs = append(pkgs, "github.com/lib/pq")
+			}
+			if settings.PackageMap[r.PkgName()].EmitErrClassifier {
+				imps = append(imps, "errors")
+				if len(pkgs) == 0 {
+					pkgs = append(pkgs, "github.com/lib/pq")
+				}
+			}
+			if settings.PackageMap[r.PkgName()].EmitInterface && settings.PackageMap[r.PkgName()].querierFile() == "" {
+				// The Querier interface names the types of every method
+				iface := MockImports(r, settings)
+				imps = mergeImports(imps, iface[0])
+				pkgs = mergeImports(pkgs, iface[1])
+			}
+			sort.Strings(imps)
+			sort.Strings(pkgs)
+			return [][]string{imps, pkgs}
 		}
 
 		if filename == "models.go" {
 			return ModelImports(r, settings)
 		}
 
+		if filename == "mock.go" {
+			return MockImports(r, settings)
+		}
+
+		if querier := settings.PackageMap[r.PkgName()].querierFile(); querier != "" && filename == querier {
+			imps := MockImports(r, settings)
+			return [][]string{imps[0], append(imps[1], settings.PackageMap[r.PkgName()].QuerierImport)}
+		}
+
+		if filename == "enums.go" {
+			if len(r.Enums(settings)) == 0 || !settings.PackageMap[r.PkgName()].EmitEnumJSON {
+				return nil
+			}
+			return [][]string{{"encoding/json", "fmt"}}
+		}
+
 		return QueryImports(r, settings, filename)
 	}
 }
 
+// mergeImports appends the paths in b missing from a
+func mergeImports(a, b []string) []string {
+	for _, path := range b {
+		found := false
+		for _, existing := range a {
+			if existing == path {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, path)
+		}
+	}
+	return a
+}
+
 func ModelImports(r Generateable, settings GenerateSettings) [][]string {
 	std := make(map[string]struct{})
 	if UsesType(r, "sql.Null", settings) {
@@ -220,12 +575,28 @@ func ModelImports(r Generateable, settings GenerateSettings) [][]string {
 	if UsesType(r, "net.IP", settings) {
 		std["net"] = struct{}{}
 	}
+	if len(r.Enums(settings)) > 0 && settings.PackageMap[r.PkgName()].EmitEnumJSON && !settings.PackageMap[r.PkgName()].EmitEnumsFile {
+		std["encoding/json"] = struct{}{}
+		std["fmt"] = struct{}{}
+	}
+	if settings.PackageMap[r.PkgName()].EmitNullTypes {
+		std["database/sql"] = struct{}{}
+		std["encoding/json"] = struct{}{}
+	}
+	if settings.PackageMap[r.PkgName()].EmitStringer && len(r.Structs(settings)) > 0 {
+		std["fmt"] = struct{}{}
+	}
+	if UsesComposites(r, settings) {
+		for _, imp := range []string{"database/sql", "database/sql/driver", "fmt", "strconv", "strings"} {
+			std[imp] = struct{}{}
+		}
+	}
 
 	// Custom imports
 	pkg := make(map[string]struct{})
 	overrideTypes := map[string]string{}
 	for _, o := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
-		if o.goBasicType {
+		if o.goBasicType || o.goTypeName == "" {
 			continue
 		}
 		overrideTypes[o.goTypeName] = o.goPackage
@@ -246,6 +617,9 @@ func ModelImports(r Generateable, settings GenerateSettings) [][]string {
 			pkg[importPath] = struct{}{}
 		}
 	}
+	if goType, importPath := settings.PackageMap[r.PkgName()].fallbackType(); importPath != "" && UsesType(r, goType, settings) {
+		pkg[importPath] = struct{}{}
+	}
 
 	pkgs := make([]string, 0, len(pkg))
 	for p, _ := range pkg {
@@ -262,6 +636,85 @@ func ModelImports(r Generateable, settings GenerateSettings) [][]string {
 	return [][]string{stds, pkgs}
 }
 
+// MockImports returns the imports for mock.go, which only needs the types in
+// the method signatures
+func MockImports(r Generateable, settings GenerateSettings) [][]string {
+	var types []string
+	for _, q := range r.GoQueries(settings) {
+		if !q.Ret.isEmpty() && !q.Ret.IsStruct() {
+			types = append(types, q.Ret.Type())
+		}
+		if q.Arg.Positional {
+			for _, f := range q.Arg.Struct.Fields {
+				types = append(types, f.Type)
+			}
+		} else if !q.Arg.isEmpty() && !q.Arg.IsStruct() {
+			types = append(types, q.Arg.Type())
+		}
+	}
+	uses := func(name string) bool {
+		for _, typ := range types {
+			if strings.HasPrefix(elemType(typ), name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	std := map[string]struct{}{
+		"context": struct{}{},
+	}
+	if uses("sql.Null") || settings.PackageMap[r.PkgName()].EmitExec {
+		std["database/sql"] = struct{}{}
+	}
+	if uses("json.RawMessage") {
+		std["encoding/json"] = struct{}{}
+	}
+	if uses("time.Time") {
+		std["time"] = struct{}{}
+	}
+	if uses("net.IP") {
+		std["net"] = struct{}{}
+	}
+
+	pkg := make(map[string]struct{})
+	overrideTypes := map[string]string{}
+	for _, o := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
+		if o.goBasicType || o.goTypeName == "" {
+			continue
+		}
+		overrideTypes[o.goTypeName] = o.goPackage
+	}
+	_, overrideNullTime := overrideTypes["pq.NullTime"]
+	if uses("pq.NullTime") && !overrideNullTime {
+		pkg["github.com/lib/pq"] = struct{}{}
+	}
+	_, overrideUUID := overrideTypes["uuid.UUID"]
+	if uses("uuid.UUID") && !overrideUUID {
+		pkg["github.com/google/uuid"] = struct{}{}
+	}
+	for goType, importPath := range overrideTypes {
+		if _, ok := std[importPath]; !ok && uses(goType) {
+			pkg[importPath] = struct{}{}
+		}
+	}
+	if goType, importPath := settings.PackageMap[r.PkgName()].fallbackType(); importPath != "" && uses(goType) {
+		pkg[importPath] = struct{}{}
+	}
+
+	pkgs := make([]string, 0, len(pkg))
+	for p := range pkg {
+		pkgs = append(pkgs, p)
+	}
+	stds := make([]string, 0, len(std))
+	for s := range std {
+		stds = append(stds, s)
+	}
+	sort.Strings(stds)
+	sort.Strings(pkgs)
+	return [][]string{stds, pkgs}
+}
+
 func QueryImports(r Generateable, settings GenerateSettings, filename string) [][]string {
 	// for _, strct := range r.Structs() {
 	// 	for _, f := range strct.Fields {
@@ -282,26 +735,26 @@ func QueryImports(r Generateable, settings GenerateSettings, filename string) []
 			if !q.Ret.isEmpty() {
 				if q.Ret.EmitStruct() {
 					for _, f := range q.Ret.Struct.Fields {
-						fType := strings.TrimPrefix(f.Type, "[]")
+						fType := elemType(f.Type)
 						if strings.HasPrefix(fType, name) {
 							return true
 						}
 					}
 				}
-				if strings.HasPrefix(q.Ret.Type(), name) {
+				if strings.HasPrefix(elemType(q.Ret.Type()), name) {
 					return true
 				}
 			}
 			if !q.Arg.isEmpty() {
-				if q.Arg.EmitStruct() {
+				if q.Arg.EmitStruct() || q.Arg.Positional {
 					for _, f := range q.Arg.Struct.Fields {
-						fType := strings.TrimPrefix(f.Type, "[]")
+						fType := elemType(f.Type)
 						if strings.HasPrefix(fType, name) {
 							return true
 						}
 					}
 				}
-				if strings.HasPrefix(q.Arg.Type(), name) {
+				if strings.HasPrefix(elemType(q.Arg.Type()), name) {
 					return true
 				}
 			}
@@ -347,6 +800,25 @@ func QueryImports(r Generateable, settings GenerateSettings, filename string) []
 	if uses("sql.Null") {
 		std["database/sql"] = struct{}{}
 	}
+	for _, q := range gq {
+		noRows := settings.PackageMap[r.PkgName()].EmitErrNotFound || settings.PackageMap[r.PkgName()].EmitZeroOnNoRows
+		if q.RetPointer || (q.Cmd == ":one" && noRows) {
+			std["database/sql"] = struct{}{}
+		}
+	}
+	if settings.PackageMap[r.PkgName()].EmitStringer {
+		for _, q := range gq {
+			if q.Arg.EmitStruct() || q.Ret.EmitStruct() {
+				std["fmt"] = struct{}{}
+			}
+		}
+	}
+	for _, q := range gq {
+		if q.Cmd == ":execmany" {
+			std["fmt"] = struct{}{}
+			std["strings"] = struct{}{}
+		}
+	}
 	if uses("json.RawMessage") {
 		std["encoding/json"] = struct{}{}
 	}
@@ -360,7 +832,7 @@ func QueryImports(r Generateable, settings GenerateSettings, filename string) []
 	pkg := make(map[string]struct{})
 	overrideTypes := map[string]string{}
 	for _, o := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
-		if o.goBasicType {
+		if o.goBasicType || o.goTypeName == "" {
 			continue
 		}
 		overrideTypes[o.goTypeName] = o.goPackage
@@ -384,6 +856,9 @@ func QueryImports(r Generateable, settings GenerateSettings, filename string) []
 			pkg[importPath] = struct{}{}
 		}
 	}
+	if goType, importPath := settings.PackageMap[r.PkgName()].fallbackType(); importPath != "" && uses(goType) {
+		pkg[importPath] = struct{}{}
+	}
 
 	pkgs := make([]string, 0, len(pkg))
 	for p, _ := range pkg {
@@ -412,9 +887,26 @@ func enumValueName(value string) string {
 	return name
 }
 
+// schemaNames returns the names of the catalog's schemas, public first and
+// the rest sorted, so that generated code doesn't depend on map order
+func schemaNames(c core.Catalog) []string {
+	names := make([]string, 0, len(c.Schemas))
+	for name := range c.Schemas {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "public" || names[j] == "public" {
+			return names[i] == "public" && names[j] != "public"
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
 func (r Result) Enums(settings GenerateSettings) []GoEnum {
 	var enums []GoEnum
-	for name, schema := range r.Catalog.Schemas {
+	for _, name := range schemaNames(r.Catalog) {
+		schema := r.Catalog.Schemas[name]
 		if name == "pg_catalog" {
 			continue
 		}
@@ -440,7 +932,48 @@ func (r Result) Enums(settings GenerateSettings) []GoEnum {
 		}
 	}
 	if len(enums) > 0 {
-		sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+		sort.SliceStable(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	}
+	return enums
+}
+
+// CheckEnums returns a set of string constants for every table column limited
+// to a list of values by a CHECK constraint
+func (r Result) CheckEnums(settings GenerateSettings) []GoEnum {
+	var enums []GoEnum
+	for _, name := range schemaNames(r.Catalog) {
+		schema := r.Catalog.Schemas[name]
+		if name == "pg_catalog" {
+			continue
+		}
+		for _, table := range schema.Tables {
+			if settings.PackageMap[r.PkgName()].ExcludesTable(name, table.Name) {
+				continue
+			}
+			tableName := table.Name
+			if name != "public" {
+				tableName = name + "_" + table.Name
+			}
+			for _, column := range table.Columns {
+				if len(column.CheckValues) == 0 {
+					continue
+				}
+				e := GoEnum{
+					Name:    inflection.Singular(StructName(tableName, settings)) + StructName(column.Name, settings),
+					Comment: table.Name + "." + column.Name,
+				}
+				for _, v := range column.CheckValues {
+					e.Constants = append(e.Constants, GoConstant{
+						Name:  e.Name + enumValueName(v),
+						Value: v,
+					})
+				}
+				enums = append(enums, e)
+			}
+		}
+	}
+	if len(enums) > 0 {
+		sort.SliceStable(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
 	}
 	return enums
 }
@@ -450,7 +983,7 @@ func StructName(name string, settings GenerateSettings) string {
 		return rename
 	}
 	out := ""
-	for _, p := range strings.Split(name, "_") {
+	for _, p := range strings.Split(identPattern.ReplaceAllString(name, "_"), "_") {
 		if p == "id" {
 			out += "ID"
 		} else {
@@ -460,13 +993,35 @@ func StructName(name string, settings GenerateSettings) string {
 	return out
 }
 
+// usedTables returns the set of tables referenced by at least one query
+func (r Result) usedTables() map[core.FQN]bool {
+	used := map[core.FQN]bool{}
+	for _, q := range r.Queries {
+		for _, fqn := range q.Tables {
+			used[fqn] = true
+		}
+	}
+	return used
+}
+
 func (r Result) Structs(settings GenerateSettings) []GoStruct {
 	var structs []GoStruct
-	for name, schema := range r.Catalog.Schemas {
+	var used map[core.FQN]bool
+	if settings.PackageMap[r.PkgName()].OmitUnusedStructs {
+		used = r.usedTables()
+	}
+	for _, name := range schemaNames(r.Catalog) {
+		schema := r.Catalog.Schemas[name]
 		if name == "pg_catalog" {
 			continue
 		}
 		for _, table := range schema.Tables {
+			if settings.PackageMap[r.PkgName()].ExcludesTable(name, table.Name) {
+				continue
+			}
+			if used != nil && !used[core.FQN{Schema: name, Rel: table.Name}] {
+				continue
+			}
 			var tableName string
 			if name == "public" {
 				tableName = table.Name
@@ -478,19 +1033,40 @@ func (r Result) Structs(settings GenerateSettings) []GoStruct {
 				Name:    inflection.Singular(StructName(tableName, settings)),
 				Comment: table.Comment,
 			}
-			for _, column := range table.Columns {
+			for i, column := range table.Columns {
 				s.Fields = append(s.Fields, GoField{
-					Name:    StructName(column.Name, settings),
+					Name:    r.goFieldName(column, i, settings),
 					Type:    r.goType(column, settings),
-					Tags:    map[string]string{"json:": column.Name},
+					Tags:    r.structTags(column, column.Name, settings),
 					Comment: column.Comment,
+					JSON:    r.isJSON(column, settings),
+				})
+			}
+			structs = append(structs, s)
+		}
+		for _, typ := range schema.CompositeTypes {
+			typeName := typ.Name
+			if name != "public" {
+				typeName = name + "_" + typ.Name
+			}
+			s := GoStruct{
+				Table:     core.FQN{Schema: name, Rel: typ.Name},
+				Name:      StructName(typeName, settings),
+				Comment:   typ.Comment,
+				Composite: true,
+			}
+			for i, column := range typ.Columns {
+				s.Fields = append(s.Fields, GoField{
+					Name: r.goFieldName(column, i, settings),
+					Type: r.goType(column, settings),
+					Tags: r.structTags(column, column.Name, settings),
 				})
 			}
 			structs = append(structs, s)
 		}
 	}
 	if len(structs) > 0 {
-		sort.Slice(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
+		sort.SliceStable(structs, func(i, j int) bool { return structs[i].Name < structs[j].Name })
 	}
 	return structs
 }
@@ -498,25 +1074,154 @@ func (r Result) Structs(settings GenerateSettings) []GoStruct {
 func (r Result) goType(col core.Column, settings GenerateSettings) string {
 	// package overrides have a higher precedence
 	for _, oride := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
+		if oride.goTypeName == "" {
+			continue
+		}
 		if oride.Column != "" && oride.columnName == col.Name && oride.table == col.Table {
 			return oride.goTypeName
 		}
 	}
+	for _, oride := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
+		if oride.Nullable != nil && oride.columnName == col.Name && oride.table == col.Table {
+			col.NotNull = !*oride.Nullable
+			break
+		}
+	}
 	typ := r.goInnerType(col, settings)
+	if settings.PackageMap[r.PkgName()].EmitNullTypes && strings.HasPrefix(typ, "sql.Null") {
+		typ = strings.TrimPrefix(typ, "sql.")
+	}
 	if col.IsArray {
+		if settings.PackageMap[r.PkgName()].EmitNullableArrays && !col.NotNull {
+			return "*[]" + typ
+		}
 		return "[]" + typ
 	}
 	return typ
 }
 
-func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
-	columnType := col.DataType
-	notNull := col.NotNull || col.IsArray
-
-	// package overrides have a higher precedence
+// isJSON reports whether the column's Go type comes from an override with
+// `json` set. Arrays are left to pq.Array.
+func (r Result) isJSON(col core.Column, settings GenerateSettings) bool {
+	if col.IsArray {
+		return false
+	}
+	typ := r.goType(col, settings)
 	for _, oride := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
-		if oride.PostgresType != "" && oride.PostgresType == columnType && oride.Null != notNull {
-			return oride.goTypeName
+		if oride.JSON && oride.goTypeName == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// StructTags returns the JSON and DB tags of a field for a column named name
+func StructTags(name string, pkg PackageSettings) map[string]string {
+	tags := map[string]string{"json:": jsonTagName(name, pkg.JSONTagsCaseStyle)}
+	if pkg.EmitDBTags {
+		tags["db:"] = name
+	}
+	return tags
+}
+
+// structTags returns the struct tags for a field generated from a column
+func (r Result) structTags(col core.Column, name string, settings GenerateSettings) map[string]string {
+	pkg := settings.PackageMap[r.PkgName()]
+	tags := StructTags(name, pkg)
+	for _, oride := range append(settings.Overrides, pkg.Overrides...) {
+		if oride.Column != "" && oride.columnName == col.Name && oride.table == col.Table {
+			for key, val := range oride.GoStructTags {
+				tags[key+":"] = val
+			}
+		}
+	}
+	return tags
+}
+
+func jsonTagName(name, style string) string {
+	switch style {
+	case "camel", "pascal":
+		out := ""
+		for i, p := range strings.Split(name, "_") {
+			if i == 0 && style == "camel" {
+				out += strings.ToLower(p)
+			} else {
+				out += strings.Title(p)
+			}
+		}
+		return out
+	default:
+		return name
+	}
+}
+
+// goFieldName returns the struct field name for a column. A column override
+// with `go_field_name` set takes precedence over the generated name.
+func (r Result) goFieldName(col core.Column, pos int, settings GenerateSettings) string {
+	for _, oride := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
+		if oride.GoFieldName != "" && oride.Column != "" && oride.columnName == col.Name && oride.table == col.Table {
+			return oride.GoFieldName
+		}
+	}
+	return StructName(columnName(col, pos), settings)
+}
+
+// sameType reports whether two type names are equal once a pg_catalog schema
+// is dropped, e.g. timestamptz and pg_catalog.timestamptz
+func sameType(a, b string) bool {
+	return strings.TrimPrefix(a, "pg_catalog.") == strings.TrimPrefix(b, "pg_catalog.")
+}
+
+func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
+	columnType := col.DataType
+	notNull := col.NotNull || col.IsArray
+
+	// package overrides have a higher precedence, and an override limited to
+	// the column's table wins over one for primary keys, which wins over one
+	// matching the type alone
+	var keyOverride, typeOverride string
+	for _, oride := range append(settings.Overrides, settings.PackageMap[r.PkgName()].Overrides...) {
+		if oride.goTypeName == "" {
+			continue
+		}
+		if oride.PrimaryKey {
+			if col.PrimaryKey && keyOverride == "" && (oride.PostgresType == "" || sameType(oride.PostgresType, columnType)) {
+				keyOverride = oride.goTypeName
+			}
+			continue
+		}
+		if oride.PostgresType == "" || !sameType(oride.PostgresType, columnType) || oride.Null == notNull {
+			continue
+		}
+		if oride.Table != "" {
+			if oride.table == col.Table {
+				return oride.goTypeName
+			}
+			continue
+		}
+		if typeOverride == "" {
+			typeOverride = oride.goTypeName
+		}
+	}
+	if keyOverride != "" {
+		return keyOverride
+	}
+	if typeOverride != "" {
+		return typeOverride
+	}
+
+	if settings.PackageMap[r.PkgName()].EmitGoInt {
+		switch columnType {
+		case "serial", "pg_catalog.serial4",
+			"bigserial", "pg_catalog.serial8",
+			"smallserial", "pg_catalog.serial2",
+			"integer", "int", "int4", "pg_catalog.int4",
+			"bigint", "pg_catalog.int8",
+			"smallint", "int2", "pg_catalog.int2":
+			if notNull {
+				return "int"
+			}
+			return "sql.NullInt64"
 		}
 	}
 
@@ -534,7 +1239,10 @@ func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
 		return "sql.NullInt64"
 
 	case "smallserial", "pg_catalog.serial2":
-		return "int16"
+		if notNull {
+			return "int16"
+		}
+		return "sql.NullInt32"
 
 	case "integer", "int", "int4", "pg_catalog.int4":
 		if notNull {
@@ -548,8 +1256,19 @@ func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
 		}
 		return "sql.NullInt64"
 
-	case "smallint", "pg_catalog.int2":
-		return "int16"
+	case "smallint", "int2", "pg_catalog.int2":
+		if notNull {
+			return "int16"
+		}
+		// database/sql has no NullInt16
+		return "sql.NullInt32"
+
+	case "oid", "pg_catalog.oid":
+		// object identifiers are unsigned four-byte integers
+		if notNull {
+			return "uint32"
+		}
+		return "sql.NullInt64"
 
 	case "float", "double precision", "pg_catalog.float8":
 		if notNull {
@@ -573,13 +1292,13 @@ func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
 		}
 		return "sql.NullString"
 
-	case "bool", "pg_catalog.bool":
+	case "bool", "boolean", "pg_catalog.bool":
 		if notNull {
 			return "bool"
 		}
 		return "sql.NullBool"
 
-	case "jsonb":
+	case "json", "jsonb", "pg_catalog.json", "pg_catalog.jsonb":
 		return "json.RawMessage"
 
 	case "bytea", "blob", "pg_catalog.bytea":
@@ -591,19 +1310,52 @@ func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
 		}
 		return "sql.NullTime"
 
-	case "pg_catalog.time", "pg_catalog.timetz":
+	case "pg_catalog.time", "pg_catalog.timetz", "timetz":
+		if notNull {
+			return "time.Time"
+		}
+		return "sql.NullTime"
+
+	case "pg_catalog.timestamp":
 		if notNull {
 			return "time.Time"
 		}
 		return "sql.NullTime"
 
-	case "pg_catalog.timestamp", "pg_catalog.timestamptz", "timestamptz":
+	case "pg_catalog.timestamptz", "timestamptz":
 		if notNull {
 			return "time.Time"
 		}
 		return "sql.NullTime"
 
-	case "text", "pg_catalog.varchar", "pg_catalog.bpchar", "string":
+	case "pg_catalog.interval", "interval":
+		// Intervals don't fit time.Duration, which can't hold months or days
+		// of varying length, so they're scanned as their text representation.
+		if notNull {
+			return "string"
+		}
+		return "sql.NullString"
+
+	case "int4range", "int8range", "numrange", "tsrange", "tstzrange", "daterange",
+		"pg_catalog.int4range", "pg_catalog.int8range", "pg_catalog.numrange",
+		"pg_catalog.tsrange", "pg_catalog.tstzrange", "pg_catalog.daterange":
+		// Ranges are scanned as their text representation, e.g. [1,10)
+		if notNull {
+			return "string"
+		}
+		return "sql.NullString"
+
+	case "money", "pg_catalog.money":
+		// Money is scanned as text formatted with the server's lc_monetary,
+		// e.g. $1,000.00, so it isn't parsed as a number
+		if notNull {
+			return "string"
+		}
+		return "sql.NullString"
+
+	case "text", "pg_catalog.text", "pg_catalog.varchar", "pg_catalog.bpchar", "bpchar", "string":
+		// char(n) and character(n) are stored as bpchar. Values are padded with
+		// spaces to n characters, and are scanned with the padding intact.
 		if notNull {
 			return "string"
 		}
@@ -621,15 +1373,17 @@ func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
 		return "sql.NullBool"
 
 	case "any":
-		return "interface{}"
+		typ, _ := settings.PackageMap[r.PkgName()].fallbackType()
+		return typ
 
 	default:
-		for name, schema := range r.Catalog.Schemas {
+		for _, name := range schemaNames(r.Catalog) {
+			schema := r.Catalog.Schemas[name]
 			if name == "pg_catalog" {
 				continue
 			}
 			for _, enum := range schema.Enums {
-				if columnType == enum.Name {
+				if columnType == enum.Name || columnType == name+"."+enum.Name {
 					if name == "public" {
 						return StructName(enum.Name, settings)
 					}
@@ -637,9 +1391,18 @@ func (r Result) goInnerType(col core.Column, settings GenerateSettings) string {
 					return StructName(name+"_"+enum.Name, settings)
 				}
 			}
+			for _, typ := range schema.CompositeTypes {
+				if columnType == typ.Name || columnType == name+"."+typ.Name {
+					if name == "public" {
+						return StructName(typ.Name, settings)
+					}
+					return StructName(name+"_"+typ.Name, settings)
+				}
+			}
 		}
 		log.Printf("unknown PostgreSQL type: %s\n", columnType)
-		return "interface{}"
+		typ, _ := settings.PackageMap[r.PkgName()].fallbackType()
+		return typ
 	}
 }
 
@@ -657,15 +1420,17 @@ func (r Result) columnsToStruct(name string, columns []core.Column, settings Gen
 	seen := map[string]int{}
 	for i, c := range columns {
 		tagName := c.Name
-		fieldName := StructName(columnName(c, i), settings)
+		fieldName := r.goFieldName(c, i, settings)
 		if v := seen[c.Name]; v > 0 {
 			tagName = fmt.Sprintf("%s_%d", tagName, v+1)
 			fieldName = fmt.Sprintf("%s_%d", fieldName, v+1)
 		}
 		gs.Fields = append(gs.Fields, GoField{
-			Name: fieldName,
-			Type: r.goType(c, settings),
-			Tags: map[string]string{"json:": tagName},
+			Name:    fieldName,
+			Type:    r.goType(c, settings),
+			Tags:    r.structTags(c, tagName, settings),
+			Comment: c.Comment,
+			JSON:    r.isJSON(c, settings),
 		})
 		seen[c.Name]++
 	}
@@ -674,15 +1439,24 @@ func (r Result) columnsToStruct(name string, columns []core.Column, settings Gen
 
 func argName(name string) string {
 	out := ""
-	for i, p := range strings.Split(name, "_") {
+	for i, p := range strings.Split(identPattern.ReplaceAllString(name, "_"), "_") {
 		if i == 0 {
-			out += strings.ToLower(p)
+			// Keep the casing of camelCase names such as a quoted "userId"
+			if p == "" || p == strings.ToUpper(p) {
+				out += strings.ToLower(p)
+			} else {
+				out += LowerTitle(p)
+			}
 		} else if p == "id" {
 			out += "ID"
 		} else {
 			out += strings.Title(p)
 		}
 	}
+	// A column named e.g. type can't be used as is for an argument
+	if token.Lookup(out).IsKeyword() {
+		out += "_"
+	}
 	return out
 }
 
@@ -693,6 +1467,26 @@ func paramName(p Parameter) string {
 	return fmt.Sprintf("dollar_%d", p.Number)
 }
 
+// paramsToPositional names each parameter as a function argument, adding a
+// numeric suffix when two parameters share a name
+func (r Result) paramsToPositional(params []Parameter, settings GenerateSettings) *GoStruct {
+	gs := GoStruct{}
+	seen := map[string]int{}
+	for _, p := range params {
+		name := paramName(p)
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, seen[name])
+		}
+		gs.Fields = append(gs.Fields, GoField{
+			Name: name,
+			Type: r.goType(p.Column, settings),
+			JSON: r.isJSON(p.Column, settings),
+		})
+	}
+	return &gs
+}
+
 func columnName(c core.Column, pos int) string {
 	if c.Name != "" {
 		return c.Name
@@ -722,23 +1516,32 @@ func (r Result) GoQueries(settings GenerateSettings) []GoQuery {
 			continue
 		}
 
+		methodName, constantName := QueryIdentifiers(query.Name, settings.PackageMap[r.PkgName()])
 		gq := GoQuery{
 			Cmd:          query.Cmd,
-			ConstantName: LowerTitle(query.Name),
-			FieldName:    LowerTitle(query.Name) + "Stmt",
-			MethodName:   query.Name,
+			ConstantName: constantName,
+			FieldName:    QueryFieldName(query.Name, settings.PackageMap[r.PkgName()]),
+			MethodName:   methodName,
 			SourceName:   query.Filename,
 			SQL:          query.SQL,
 			Comments:     query.Comments,
 		}
 
-		if len(query.Params) == 1 {
-			p := query.Params[0]
+		switch limit := settings.PackageMap[r.PkgName()].ParamsStructLimit(); {
+		case query.Cmd == ":execmany":
+			var cols []core.Column
+			for _, p := range query.Params {
+				cols = append(cols, p.Column)
+			}
 			gq.Arg = GoQueryValue{
-				Name: paramName(p),
-				Typ:  r.goType(p.Column, settings),
+				Emit:   true,
+				Name:   "arg",
+				Struct: r.columnsToStruct(settings.PackageMap[r.PkgName()].ParamsStructName(gq.MethodName), cols, settings),
+				Slice:  true,
 			}
-		} else if len(query.Params) > 1 {
+			gq.Values = goValues(query.SQL, gq.Arg)
+		case len(query.Params) == 0:
+		case len(query.Params) >= limit:
 			var cols []core.Column
 			for _, p := range query.Params {
 				cols = append(cols, p.Column)
@@ -746,7 +1549,19 @@ func (r Result) GoQueries(settings GenerateSettings) []GoQuery {
 			gq.Arg = GoQueryValue{
 				Emit:   true,
 				Name:   "arg",
-				Struct: r.columnsToStruct(gq.MethodName+"Params", cols, settings),
+				Struct: r.columnsToStruct(settings.PackageMap[r.PkgName()].ParamsStructName(gq.MethodName), cols, settings),
+			}
+		case len(query.Params) == 1:
+			p := query.Params[0]
+			gq.Arg = GoQueryValue{
+				Name: paramName(p),
+				Typ:  r.goType(p.Column, settings),
+				JSON: r.isJSON(p.Column, settings),
+			}
+		default:
+			gq.Arg = GoQueryValue{
+				Positional: true,
+				Struct:     r.paramsToPositional(query.Params, settings),
 			}
 		}
 
@@ -755,6 +1570,7 @@ func (r Result) GoQueries(settings GenerateSettings) []GoQuery {
 			gq.Ret = GoQueryValue{
 				Name: columnName(c, 0),
 				Typ:  r.goType(c, settings),
+				JSON: r.isJSON(c, settings),
 			}
 		} else if len(query.Columns) > 1 {
 			var gs *GoStruct
@@ -767,7 +1583,7 @@ func (r Result) GoQueries(settings GenerateSettings) []GoQuery {
 				same := true
 				for i, f := range s.Fields {
 					c := query.Columns[i]
-					sameName := f.Name == StructName(columnName(c, i), settings)
+					sameName := f.Name == r.goFieldName(c, i, settings)
 					sameType := f.Type == r.goType(c, settings)
 					sameTable := s.Table.Catalog == c.Table.Catalog && s.Table.Schema == c.Table.Schema && s.Table.Rel == c.Table.Rel
 
@@ -782,7 +1598,7 @@ func (r Result) GoQueries(settings GenerateSettings) []GoQuery {
 			}
 
 			if gs == nil {
-				gs = r.columnsToStruct(gq.MethodName+"Row", query.Columns, settings)
+				gs = r.columnsToStruct(settings.PackageMap[r.PkgName()].RowStructName(gq.MethodName), query.Columns, settings)
 				emit = true
 			}
 			gq.Ret = GoQueryValue{
@@ -791,6 +1607,7 @@ func (r Result) GoQueries(settings GenerateSettings) []GoQuery {
 				Struct: gs,
 			}
 		}
+		gq.RetPointer = gq.Cmd == ":one" && gq.Ret.IsStruct() && settings.PackageMap[r.PkgName()].EmitResultPointers
 
 		qs = append(qs, gq)
 	}
@@ -816,7 +1633,7 @@ type DBTX interface {
 	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
 }
 
-func New(db DBTX) *Queries {
+func {{.Constructor}}(db DBTX) *Queries {
 	return &Queries{db: db}
 }
 
@@ -824,10 +1641,10 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
-	{{- if eq (len .GoQueries) 0 }}
+	{{- if eq (len .PreparedQueries) 0 }}
 	_ = err
 	{{- end }}
-	{{- range .GoQueries }}
+	{{- range .PreparedQueries }}
 	if q.{{.FieldName}}, err = db.PrepareContext(ctx, {{.ConstantName}}); err != nil {
 		return nil, fmt.Errorf("error preparing query {{.MethodName}}: %w", err)
 	}
@@ -835,48 +1652,61 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	return &q, nil
 }
 
-func (q *Queries) Close() error {
+// Close closes every prepared statement. The error wraps the first failure
+// and lists the rest.
+func ({{$.Receiver}} *Queries) Close() error {
 	var err error
-	{{- range .GoQueries }}
-	if q.{{.FieldName}} != nil {
-		if cerr := q.{{.FieldName}}.Close(); cerr != nil {
-			err = fmt.Errorf("error closing {{.FieldName}}: %w", cerr)
+	{{- range .PreparedQueries }}
+	if {{$.Receiver}}.{{.FieldName}} != nil {
+		if cerr := {{$.Receiver}}.{{.FieldName}}.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("error closing query {{.MethodName}}: %w", cerr)
+		} else if cerr != nil {
+			err = fmt.Errorf("%w; error closing query {{.MethodName}}: %v", err, cerr)
 		}
 	}
 	{{- end}}
 	return err
 }
+{{if .EmitStmtAccessors}}
+{{- range .PreparedQueries }}
+// {{.MethodName}}Stmt returns the prepared statement for {{.MethodName}}, or nil
+// if the queries were not created with Prepare.
+func ({{$.Receiver}} *Queries) {{.MethodName}}Stmt() *sql.Stmt {
+	return {{$.Receiver}}.{{.FieldName}}
+}
+{{end}}
+{{- end}}
 
-func (q *Queries) exec(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (sql.Result, error) {
+func ({{$.Receiver}} *Queries) exec(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (sql.Result, error) {
 	switch {
-	case stmt != nil && q.tx != nil:
-		return q.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+	case stmt != nil && {{$.Receiver}}.tx != nil:
+		return {{$.Receiver}}.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
 	case stmt != nil:
 		return stmt.ExecContext(ctx, args...)
 	default:
-		return q.db.ExecContext(ctx, query, args...)
+		return {{$.Receiver}}.db.ExecContext(ctx, query, args...)
 	}
 }
 
-func (q *Queries) query(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error) {
+func ({{$.Receiver}} *Queries) query(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Rows, error) {
 	switch {
-	case stmt != nil && q.tx != nil:
-		return q.tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
+	case stmt != nil && {{$.Receiver}}.tx != nil:
+		return {{$.Receiver}}.tx.StmtContext(ctx, stmt).QueryContext(ctx, args...)
 	case stmt != nil:
 		return stmt.QueryContext(ctx, args...)
 	default:
-		return q.db.QueryContext(ctx, query, args...)
+		return {{$.Receiver}}.db.QueryContext(ctx, query, args...)
 	}
 }
 
-func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Row) {
+func ({{$.Receiver}} *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, args ...interface{}) (*sql.Row) {
 	switch {
-	case stmt != nil && q.tx != nil:
-		return q.tx.StmtContext(ctx, stmt).QueryRowContext(ctx, args...)
+	case stmt != nil && {{$.Receiver}}.tx != nil:
+		return {{$.Receiver}}.tx.StmtContext(ctx, stmt).QueryRowContext(ctx, args...)
 	case stmt != nil:
 		return stmt.QueryRowContext(ctx, args...)
 	default:
-		return q.db.QueryRowContext(ctx, query, args...)
+		return {{$.Receiver}}.db.QueryRowContext(ctx, query, args...)
 	}
 }
 {{end}}
@@ -886,29 +1716,211 @@ type Queries struct {
 
     {{- if .EmitPreparedQueries}}
 	tx         *sql.Tx
-	{{- range .GoQueries}}
+	{{- range .PreparedQueries}}
 	{{.FieldName}}  *sql.Stmt
 	{{- end}}
 	{{- end}}
 }
 
-func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+func ({{$.Receiver}} *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
 		db: tx,
      	{{- if .EmitPreparedQueries}}
 		tx: tx,
-		{{- range .GoQueries}}
-		{{.FieldName}}: q.{{.FieldName}},
+		{{- range .PreparedQueries}}
+		{{.FieldName}}: {{$.Receiver}}.{{.FieldName}},
 		{{- end}}
 		{{- end}}
 	}
 }
 
-{{if .EmitInterface }}
+{{if .EmitStore}}
+// Store wraps a database handle, running queries directly or, with ExecTx,
+// inside a transaction.
+type Store struct {
+	*Queries
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{Queries: {{.Constructor}}(db), db: db}
+}
+
+// ExecTx calls fn with Queries bound to a new transaction, which is committed
+// if fn returns nil and rolled back otherwise.
+func (s *Store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(s.WithTx(tx)); err != nil {
+		// The error from fn says more than any error from rolling back
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+{{end}}
+
+{{if .EmitQueryHook}}
+// QueryHook, when set, is called with the name and SQL of each query before
+// it runs, e.g. for logging or tracing.
+var QueryHook func(ctx context.Context, name, query string)
+{{end}}
+
+{{if .EmitErrNotFound}}
+// ErrNotFound is returned when a query expecting a single row finds none. It
+// wraps sql.ErrNoRows.
+var ErrNotFound = fmt.Errorf("not found: %w", sql.ErrNoRows)
+{{end}}
+
+{{if .EmitErrClassifier}}
+var (
+	// ErrUniqueViolation matches a *ConstraintError for a unique violation.
+	ErrUniqueViolation = errors.New("unique violation")
+
+	// ErrForeignKeyViolation matches a *ConstraintError for a foreign key violation.
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+)
+
+// ConstraintError is a constraint violation reported by the database. It
+// unwraps to the driver's error.
+type ConstraintError struct {
+	Kind       error
+	Constraint string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// ClassifyError turns unique and foreign key violations, identified by their
+// SQLSTATE code, into a *ConstraintError. Other errors are returned as is.
+func ClassifyError(err error) error {
+	var code, constraint string
+	var pqErr *pq.Error
+	var pgErr interface{ SQLState() string }
+	switch {
+	case errors.As(err, &pqErr):
+		code, constraint = string(pqErr.Code), pqErr.Constraint
+	case errors.As(err, &pgErr):
+		code = pgErr.SQLState()
+	default:
+		return err
+	}
+	switch code {
+	case "23505":
+		return &ConstraintError{Kind: ErrUniqueViolation, Constraint: constraint, Err: err}
+	case "23503":
+		return &ConstraintError{Kind: ErrForeignKeyViolation, Constraint: constraint, Err: err}
+	}
+	return err
+}
+{{end}}
+
+{{if .QueryTimeout}}
+// defaultQueryTimeout bounds how long each generated method waits on the database.
+const defaultQueryTimeout = {{.QueryTimeout}}
+{{end}}
+
+{{if .EmitJSONValue}}
+// jsonValue scans a column with json.Unmarshal and passes a query argument
+// with json.Marshal
+type jsonValue struct {
+	v interface{}
+}
+
+func (j jsonValue) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, j.v)
+	case string:
+		return json.Unmarshal([]byte(src), j.v)
+	}
+	return fmt.Errorf("jsonValue: cannot scan %T", src)
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	return json.Marshal(j.v)
+}
+{{end}}
+
+{{if .EmitNullArray}}
+// nullArray scans a nullable array column into a pointer to a slice, which is
+// nil for NULL, and passes a nil pointer as NULL
+type nullArray struct {
+	v interface{}
+}
+
+func (a nullArray) Scan(src interface{}) error {
+	p := reflect.ValueOf(a.v).Elem()
+	if src == nil {
+		p.Set(reflect.Zero(p.Type()))
+		return nil
+	}
+	s := reflect.New(p.Type().Elem())
+	if err := pq.Array(s.Interface()).Scan(src); err != nil {
+		return err
+	}
+	p.Set(s)
+	return nil
+}
+
+func (a nullArray) Value() (driver.Value, error) {
+	p := reflect.ValueOf(a.v)
+	if p.IsNil() {
+		return nil, nil
+	}
+	return pq.Array(p.Elem().Interface()).Value()
+}
+{{end}}
+
+{{if .EmitPing}}
+const ping = {{$.Q}}SELECT 1{{$.Q}}
+
+// Ping runs a trivial query to check that the database is reachable.
+func ({{$.Receiver}} *Queries) Ping(ctx context.Context) error {
+	var one int
+	return {{$.Receiver}}.db.QueryRowContext(ctx, ping).Scan(&one)
+}
+{{end}}
+
+{{if .EmitExec}}
+// Exec runs an arbitrary statement on the same handle as the generated queries.
+func ({{$.Receiver}} *Queries) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return {{$.Receiver}}.db.ExecContext(ctx, query, args...)
+}
+{{end}}
+
+{{if and .EmitInterface (not .QuerierPackage)}}
+{{template "querier" .}}
+
+var _ Querier = (*Queries)(nil)
+{{end}}
+`
+
+var querierIfaceTmpl = `{{define "querier"}}
 type Querier interface {
+	{{- if .EmitPing}}
+	Ping(ctx context.Context) error
+	{{- end}}
+	{{- if .EmitExec}}
+	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	{{- end}}
 	{{- range .GoQueries}}
 	{{- if eq .Cmd ":one"}}
-	{{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ({{.Ret.Type}}, error)
+	{{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ({{.OneType}}, error)
 	{{- end}}
 	{{- if eq .Cmd ":many"}}
 	{{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ([]{{.Ret.Type}}, error)
@@ -919,14 +1931,30 @@ type Querier interface {
 	{{- if eq .Cmd ":execrows"}}
 	{{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) (int64, error)
 	{{- end}}
+	{{- if eq .Cmd ":execmany"}}
+	{{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) error
+	{{- end}}
 	{{- end}}
 }
+{{end}}`
 
-var _ Querier = (*Queries)(nil)
-{{end}}
+var querierTmpl = `// Code generated by sqlc. DO NOT EDIT.
+
+package {{.QuerierPackage}}
+
+import (
+	{{range imports .SourceName}}
+	{{range .}}"{{.}}"
+	{{end}}
+	{{end}}
+)
+
+{{template "querier" .}}
+
+var _ Querier = (*{{.Package}}.Queries)(nil)
 `
 
-var modelsTmpl = `// Code generated by sqlc. DO NOT EDIT.
+var mockTmpl = `// Code generated by sqlc. DO NOT EDIT.
 
 package {{.Package}}
 
@@ -937,36 +1965,79 @@ import (
 	{{end}}
 )
 
-{{range .Enums}}
-{{if .Comment}}// {{.Comment}}{{end}}
-type {{.Name}} string
-
-const (
-	{{- range .Constants}}
-	{{.Name}} {{.Type}} = "{{.Value}}"
+// MockQuerier stands in for Queries in tests. Each method records its
+// arguments in the matching Calls field and returns the result of the matching
+// Func field, or zero values when it is nil. It is not safe for concurrent use.
+type MockQuerier struct {
+	{{- if .EmitPing}}
+	PingFunc  func(ctx context.Context) error
+	PingCalls [][]interface{}
 	{{- end}}
-)
+	{{- if .EmitExec}}
+	ExecFunc  func(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	ExecCalls [][]interface{}
+	{{- end}}
+	{{- range .GoQueries}}
+	{{.MethodName}}Func  func(ctx context.Context, {{.Arg.Pair}}) {{template "results" .}}
+	{{.MethodName}}Calls [][]interface{}
+	{{- end}}
+}
 
-func (e *{{.Name}}) Scan(src interface{}) error {
-	*e = {{.Name}}(src.([]byte))
-	return nil
+{{if .EmitPing}}
+func (m *MockQuerier) Ping(ctx context.Context) error {
+	m.PingCalls = append(m.PingCalls, []interface{}{})
+	if m.PingFunc == nil {
+		return nil
+	}
+	return m.PingFunc(ctx)
 }
 {{end}}
 
-{{range .Structs}}
-{{if .Comment}}// {{.Comment}}{{end}}
-type {{.Name}} struct { {{- range .Fields}}
-  {{- if .Comment}}
-  // {{.Comment}}{{else}}
-  {{- end}}
-  {{.Name}} {{.Type}} {{if $.EmitJSONTags}}{{$.Q}}{{.Tag}}{{$.Q}}{{end}}
-  {{- end}}
+{{if .EmitExec}}
+func (m *MockQuerier) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	m.ExecCalls = append(m.ExecCalls, append([]interface{}{query}, args...))
+	if m.ExecFunc == nil {
+		return nil, nil
+	}
+	return m.ExecFunc(ctx, query, args...)
+}
+{{end}}
+
+{{range .GoQueries}}
+func (m *MockQuerier) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) {{template "results" .}} {
+	m.{{.MethodName}}Calls = append(m.{{.MethodName}}Calls, []interface{}{ {{- .Arg.Names -}} })
+	if m.{{.MethodName}}Func == nil {
+		{{- if eq .Cmd ":one"}}
+		var zero {{.OneType}}
+		return zero, nil
+		{{- end}}
+		{{- if eq .Cmd ":many"}}
+		return nil, nil
+		{{- end}}
+		{{- if or (eq .Cmd ":exec") (eq .Cmd ":execmany")}}
+		return nil
+		{{- end}}
+		{{- if eq .Cmd ":execrows"}}
+		return 0, nil
+		{{- end}}
+	}
+	return m.{{.MethodName}}Func(ctx, {{.Arg.Names}})
 }
 {{end}}
+
+{{if and .EmitInterface (not .QuerierPackage)}}
+var _ Querier = (*MockQuerier)(nil)
+{{end}}
+
+{{define "results"}}
+{{- if eq .Cmd ":one"}}({{.OneType}}, error){{end}}
+{{- if eq .Cmd ":many"}}([]{{.Ret.Type}}, error){{end}}
+{{- if or (eq .Cmd ":exec") (eq .Cmd ":execmany")}}error{{end}}
+{{- if eq .Cmd ":execrows"}}(int64, error){{end}}
+{{- end}}
 `
 
-var sqlTmpl = `// Code generated by sqlc. DO NOT EDIT.
-// source: {{.SourceName}}
+var modelsTmpl = `// Code generated by sqlc. DO NOT EDIT.
 
 package {{.Package}}
 
@@ -977,55 +2048,430 @@ import (
 	{{end}}
 )
 
-{{range .GoQueries}}
-{{if eq .SourceName $.SourceName}}
-const {{.ConstantName}} = {{$.Q}}-- name: {{.MethodName}} {{.Cmd}}
-{{.SQL}}
-{{$.Q}}
+{{range .Enums}}
+{{if .Comment}}// {{.Comment}}{{end}}
+type {{.Name}} string
 
-{{if .Arg.EmitStruct}}
-type {{.Arg.Type}} struct { {{- range .Arg.Struct.Fields}}
-  {{.Name}} {{.Type}} {{if $.EmitJSONTags}}{{$.Q}}{{.Tag}}{{$.Q}}{{end}}
-  {{- end}}
-}
-{{end}}
+const (
+	{{- range .Constants}}
+	{{.Name}} {{.Type}} = "{{.Value}}"
+	{{- end}}
+)
 
-{{if .Ret.EmitStruct}}
+func (e *{{.Name}}) Scan(src interface{}) error {
+	*e = {{.Name}}(src.([]byte))
+	return nil
+}
+
+{{if $.EmitEnumJSON}}
+func (e {{.Name}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(e))
+}
+
+func (e *{{.Name}}) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	{{- if $.EmitEnumValid}}
+	if {{.Name}}(s).Valid() {
+		*e = {{.Name}}(s)
+		return nil
+	}
+	{{- else if .Constants}}
+	switch {{.Name}}(s) {
+	case {{range $i, $c := .Constants}}{{if $i}}, {{end}}{{$c.Name}}{{end}}:
+		*e = {{.Name}}(s)
+		return nil
+	}
+	{{- end}}
+	return fmt.Errorf("invalid {{.Name}} value %q", s)
+}
+{{end}}
+
+{{if $.EmitEnumValid}}
+// Valid reports whether e is one of the {{.Name}} values
+func (e {{.Name}}) Valid() bool {
+	{{- if .Constants}}
+	switch e {
+	case {{range $i, $c := .Constants}}{{if $i}}, {{end}}{{$c.Name}}{{end}}:
+		return true
+	}
+	{{- end}}
+	return false
+}
+{{end}}
+{{end}}
+
+{{range .CheckEnums}}
+// Values allowed by the CHECK constraint on {{.Comment}}
+const (
+	{{- range .Constants}}
+	{{.Name}} = {{printf "%q" .Value}}
+	{{- end}}
+)
+
+{{if $.EmitCheckValidators}}
+// Valid{{.Name}} reports whether s is allowed by the CHECK constraint on {{.Comment}}
+func Valid{{.Name}}(s string) bool {
+	switch s {
+	case {{range $i, $c := .Constants}}{{if $i}}, {{end}}{{$c.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+{{end}}
+{{end}}
+
+{{range .Structs}}
+{{if .Comment}}// {{.Comment}}{{end}}
+type {{.Name}} struct { {{- range .Fields}}
+  {{- if .Comment}}
+  // {{.Comment}}{{else}}
+  {{- end}}
+  {{.Name}} {{.Type}} {{$.Tag .}}
+  {{- end}}
+}
+
+{{if $.EmitStringer}}
+func (v {{.Name}}) String() string {
+	return fmt.Sprintf({{.StringerFormat}}{{range .StringerFields}}, v.{{.Name}}{{end}})
+}
+{{end}}
+
+{{if $.EmitDeepCopy}}
+// DeepCopy returns a copy of v that shares no slices or pointers with it
+func (v {{.Name}}) DeepCopy() {{.Name}} {
+	c := v
+	{{- range .DeepCopyStmts}}
+	{{.}}
+	{{- end}}
+	return c
+}
+{{end}}
+
+{{if .Composite}}
+{{- $name := .Name}}
+func (c *{{.Name}}) Scan(src interface{}) error {
+	if src == nil {
+		*c = {{.Name}}{}
+		return nil
+	}
+	fields, err := parseComposite(src)
+	if err != nil {
+		return err
+	}
+	if len(fields) != {{len .Fields}} {
+		return fmt.Errorf("{{.Name}}: expected {{len .Fields}} fields, got %d", len(fields))
+	}
+	{{- range $i, $f := .Fields}}
+	if err := scanCompositeField(&c.{{$f.Name}}, fields[{{$i}}]); err != nil {
+		return fmt.Errorf("{{$name}}.{{$f.Name}}: %w", err)
+	}
+	{{- end}}
+	return nil
+}
+
+func (c {{.Name}}) Value() (driver.Value, error) {
+	return formatComposite({{range $i, $f := .Fields}}{{if $i}}, {{end}}c.{{$f.Name}}{{end}})
+}
+{{end}}
+{{end}}
+
+{{range .NullTypes}}
+// {{.Name}} is a sql.{{.Name}} that marshals to JSON as its value or null
+type {{.Name}} struct {
+	sql.{{.Name}}
+}
+
+func (n {{.Name}}) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.{{.Field}})
+}
+
+func (n *{{.Name}}) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*n = {{.Name}}{}
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.{{.Field}}); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+{{end}}
+
+{{if .HasComposites}}
+// parseComposite splits the text form of a composite value, e.g. (1,hello),
+// into its fields. NULL fields are returned as nil.
+func parseComposite(src interface{}) ([]*string, error) {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return nil, fmt.Errorf("cannot scan %T into a composite type", src)
+	}
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("invalid composite value %q", s)
+	}
+	var fields []*string
+	var field strings.Builder
+	var quoted, inQuotes, escaped bool
+	flush := func() {
+		if field.Len() == 0 && !quoted {
+			fields = append(fields, nil)
+		} else {
+			v := field.String()
+			fields = append(fields, &v)
+		}
+		field.Reset()
+		quoted = false
+	}
+	body := s[1 : len(s)-1]
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case escaped:
+			field.WriteByte(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case inQuotes && c == '"':
+			if i+1 < len(body) && body[i+1] == '"' {
+				field.WriteByte('"')
+				i++
+			} else {
+				inQuotes = false
+			}
+		case c == '"':
+			inQuotes, quoted = true, true
+		case !inQuotes && c == ',':
+			flush()
+		default:
+			field.WriteByte(c)
+		}
+	}
+	flush()
+	return fields, nil
+}
+
+// scanCompositeField converts a single composite field into dest
+func scanCompositeField(dest interface{}, src *string) error {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		if src == nil {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan([]byte(*src))
+	}
+	if src == nil {
+		return fmt.Errorf("cannot scan NULL into %T", dest)
+	}
+	var err error
+	switch d := dest.(type) {
+	case *string:
+		*d = *src
+	case *bool:
+		*d, err = strconv.ParseBool(*src)
+	case *int16:
+		var v int64
+		v, err = strconv.ParseInt(*src, 10, 16)
+		*d = int16(v)
+	case *int32:
+		var v int64
+		v, err = strconv.ParseInt(*src, 10, 32)
+		*d = int32(v)
+	case *int64:
+		*d, err = strconv.ParseInt(*src, 10, 64)
+	case *float32:
+		var v float64
+		v, err = strconv.ParseFloat(*src, 32)
+		*d = float32(v)
+	case *float64:
+		*d, err = strconv.ParseFloat(*src, 64)
+	default:
+		return fmt.Errorf("cannot scan composite field into %T", dest)
+	}
+	return err
+}
+
+// formatComposite encodes values in the text form of a composite value
+func formatComposite(values ...interface{}) (driver.Value, error) {
+	quote := strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+	fields := make([]string, len(values))
+	for i, v := range values {
+		if valuer, ok := v.(driver.Valuer); ok {
+			var err error
+			if v, err = valuer.Value(); err != nil {
+				return nil, err
+			}
+		}
+		switch t := v.(type) {
+		case nil:
+			continue
+		case bool:
+			fields[i] = strconv.FormatBool(t)
+		case []byte:
+			fields[i] = "\"" + quote.Replace(string(t)) + "\""
+		default:
+			fields[i] = "\"" + quote.Replace(fmt.Sprint(t)) + "\""
+		}
+	}
+	return "(" + strings.Join(fields, ",") + ")", nil
+}
+{{end}}
+`
+
+var queriesTmpl = `// Code generated by sqlc. DO NOT EDIT.
+
+package {{.Package}}
+
+{{range .GoQueries}}
+const {{.ConstantName}} = {{$.Q}}-- name: {{.MethodName}} {{.Cmd}}
+{{.SQL}}
+{{$.Q}}
+{{end}}
+`
+
+var sqlTmpl = `// Code generated by sqlc. DO NOT EDIT.
+// source: {{.SourceName}}
+
+package {{.Package}}
+
+import (
+	{{range imports .SourceName}}
+	{{range .}}"{{.}}"
+	{{end}}
+	{{end}}
+)
+
+{{range .GoQueries}}
+{{if eq .SourceName $.SourceName}}
+{{if not $.EmitQueriesFile}}
+const {{.ConstantName}} = {{$.Q}}-- name: {{.MethodName}} {{.Cmd}}
+{{.SQL}}
+{{$.Q}}
+{{end}}
+
+{{if .Arg.EmitStruct}}
+type {{.Arg.Type}} struct { {{- range .Arg.Struct.Fields}}
+  {{- if .Comment}}
+  // {{.Comment}}{{else}}
+  {{- end}}
+  {{.Name}} {{.Type}} {{$.Tag .}}
+  {{- end}}
+}
+{{if $.EmitStringer}}
+func (v {{.Arg.Type}}) String() string {
+	return fmt.Sprintf({{.Arg.Struct.StringerFormat}}{{range .Arg.Struct.StringerFields}}, v.{{.Name}}{{end}})
+}
+{{end}}
+{{end}}
+
+{{if .Ret.EmitStruct}}
 type {{.Ret.Type}} struct { {{- range .Ret.Struct.Fields}}
-  {{.Name}} {{.Type}} {{if $.EmitJSONTags}}{{$.Q}}{{.Tag}}{{$.Q}}{{end}}
+  {{- if .Comment}}
+  // {{.Comment}}{{else}}
+  {{- end}}
+  {{.Name}} {{.Type}} {{$.Tag .}}
   {{- end}}
 }
+{{if $.EmitStringer}}
+func (v {{.Ret.Type}}) String() string {
+	return fmt.Sprintf({{.Ret.Struct.StringerFormat}}{{range .Ret.Struct.StringerFields}}, v.{{.Name}}{{end}})
+}
+{{end}}
+{{if $.EmitDeepCopy}}
+// DeepCopy returns a copy of v that shares no slices or pointers with it
+func (v {{.Ret.Type}}) DeepCopy() {{.Ret.Type}} {
+	c := v
+	{{- range .Ret.Struct.DeepCopyStmts}}
+	{{.}}
+	{{- end}}
+	return c
+}
+{{end}}
 {{end}}
 
 {{if eq .Cmd ":one"}}
 {{range .Comments}}//{{.}}
-{{end -}}
-func (q *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ({{.Ret.Type}}, error) {
+{{end}}
+{{- if $.EmitMethodExamples}}{{range .Example $.Receiver}}//{{.}}
+{{end}}{{end -}}
+func ({{$.Receiver}} *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ({{.OneType}}, error) {
+	{{- if $.QueryTimeout}}
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+	{{- end}}
+	{{- if $.EmitQueryHook}}
+	if QueryHook != nil {
+		QueryHook(ctx, "{{.MethodName}}", {{.ConstantName}})
+	}
+	{{- end}}
   	{{- if $.EmitPreparedQueries}}
-	row := q.queryRow(ctx, q.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
+	row := {{$.Receiver}}.queryRow(ctx, {{$.Receiver}}.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
 	{{- else}}
-	row := q.db.QueryRowContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
+	row := {{$.Receiver}}.db.QueryRowContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
 	{{- end}}
 	var {{.Ret.Name}} {{.Ret.Type}}
 	err := row.Scan({{.Ret.Scan}})
+	{{- if .RetPointer}}
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Ret.Name}}, nil
+	{{- else}}
+	{{- if $.EmitZeroOnNoRows}}
+	if err == sql.ErrNoRows {
+		return {{.Ret.Name}}, nil
+	}
+	{{- else if $.EmitErrNotFound}}
+	if err == sql.ErrNoRows {
+		err = ErrNotFound
+	}
+	{{- end}}
 	return {{.Ret.Name}}, err
+	{{- end}}
 }
 {{end}}
 
 {{if eq .Cmd ":many"}}
 {{range .Comments}}//{{.}}
-{{end -}}
-func (q *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ([]{{.Ret.Type}}, error) {
+{{end}}
+{{- if $.EmitMethodExamples}}{{range .Example $.Receiver}}//{{.}}
+{{end}}{{end -}}
+func ({{$.Receiver}} *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ([]{{.Ret.Type}}, error) {
+	{{- if $.QueryTimeout}}
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+	{{- end}}
+	{{- if $.EmitQueryHook}}
+	if QueryHook != nil {
+		QueryHook(ctx, "{{.MethodName}}", {{.ConstantName}})
+	}
+	{{- end}}
   	{{- if $.EmitPreparedQueries}}
-	rows, err := q.query(ctx, q.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
+	rows, err := {{$.Receiver}}.query(ctx, {{$.Receiver}}.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
   	{{- else}}
-	rows, err := q.db.QueryContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
+	rows, err := {{$.Receiver}}.db.QueryContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
   	{{- end}}
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	{{- if $.EmitEmptySlices}}
+	items := []{{.Ret.Type}}{}
+	{{- else}}
 	var items []{{.Ret.Type}}
+	{{- end}}
 	for rows.Next() {
 		var {{.Ret.Name}} {{.Ret.Type}}
 		if err := rows.Scan({{.Ret.Scan}}); err != nil {
@@ -1045,12 +2491,23 @@ func (q *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) ([]{{.Ret.
 
 {{if eq .Cmd ":exec"}}
 {{range .Comments}}//{{.}}
-{{end -}}
-func (q *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) error {
+{{end}}
+{{- if $.EmitMethodExamples}}{{range .Example $.Receiver}}//{{.}}
+{{end}}{{end -}}
+func ({{$.Receiver}} *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) error {
+	{{- if $.QueryTimeout}}
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+	{{- end}}
+	{{- if $.EmitQueryHook}}
+	if QueryHook != nil {
+		QueryHook(ctx, "{{.MethodName}}", {{.ConstantName}})
+	}
+	{{- end}}
   	{{- if $.EmitPreparedQueries}}
-	_, err := q.exec(ctx, q.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
+	_, err := {{$.Receiver}}.exec(ctx, {{$.Receiver}}.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
   	{{- else}}
-	_, err := q.db.ExecContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
+	_, err := {{$.Receiver}}.db.ExecContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
   	{{- end}}
 	return err
 }
@@ -1058,12 +2515,23 @@ func (q *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) error {
 
 {{if eq .Cmd ":execrows"}}
 {{range .Comments}}//{{.}}
-{{end -}}
-func (q *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) (int64, error) {
+{{end}}
+{{- if $.EmitMethodExamples}}{{range .Example $.Receiver}}//{{.}}
+{{end}}{{end -}}
+func ({{$.Receiver}} *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) (int64, error) {
+	{{- if $.QueryTimeout}}
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+	{{- end}}
+	{{- if $.EmitQueryHook}}
+	if QueryHook != nil {
+		QueryHook(ctx, "{{.MethodName}}", {{.ConstantName}})
+	}
+	{{- end}}
   	{{- if $.EmitPreparedQueries}}
-	result, err := q.exec(ctx, q.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
+	result, err := {{$.Receiver}}.exec(ctx, {{$.Receiver}}.{{.FieldName}}, {{.ConstantName}}, {{.Arg.Params}})
   	{{- else}}
-	result, err := q.db.ExecContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
+	result, err := {{$.Receiver}}.db.ExecContext(ctx, {{.ConstantName}}, {{.Arg.Params}})
   	{{- end}}
 	if err != nil {
 		return 0, err
@@ -1071,6 +2539,48 @@ func (q *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) (int64, er
 	return result.RowsAffected()
 }
 {{end}}
+
+{{if eq .Cmd ":execmany"}}
+{{range .Comments}}//{{.}}
+{{end}}
+{{- if $.EmitMethodExamples}}{{range .Example $.Receiver}}//{{.}}
+{{end}}{{end -}}
+func ({{$.Receiver}} *Queries) {{.MethodName}}(ctx context.Context, {{.Arg.Pair}}) error {
+	if len({{.Arg.Name}}) == 0 {
+		return nil
+	}
+	{{- if $.QueryTimeout}}
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+	{{- end}}
+	// A statement binds at most 65535 parameters, so larger slices are
+	// inserted by several statements
+	for len({{.Arg.Name}}) > 0 {
+		batch := {{.Arg.Name}}
+		if len(batch) > {{.Values.BatchSize}} {
+			batch = batch[:{{.Values.BatchSize}}]
+		}
+		{{.Arg.Name}} = {{.Arg.Name}}[len(batch):]
+		rows := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)*{{.Values.Width}})
+		for i, a := range batch {
+			n := i * {{.Values.Width}}
+			rows[i] = fmt.Sprintf({{.Values.Row}}, {{.Values.Numbers}})
+			args = append(args, {{.Values.Args}})
+		}
+		query := {{$.Q}}{{.Values.Prefix}}{{$.Q}} + strings.Join(rows, ", ") + {{$.Q}}{{.Values.Suffix}}{{$.Q}}
+		{{- if $.EmitQueryHook}}
+		if QueryHook != nil {
+			QueryHook(ctx, "{{.MethodName}}", query)
+		}
+		{{- end}}
+		if _, err := {{$.Receiver}}.db.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{end}}
 {{end}}
 {{end}}
 `
@@ -1087,8 +2597,107 @@ type tmplCtx struct {
 	SourceName string
 
 	EmitJSONTags        bool
+	EmitDBTags          bool
 	EmitPreparedQueries bool
+	EmitStmtAccessors   bool
 	EmitInterface       bool
+	EmitPing            bool
+	EmitExec            bool
+	EmitJSONValue       bool
+	EmitNullArray       bool
+	EmitStringer        bool
+	EmitEnumJSON        bool
+	EmitEnumValid       bool
+	EmitDeepCopy        bool
+	EmitMethodExamples  bool
+	EmitEmptySlices     bool
+	EmitErrNotFound     bool
+	EmitZeroOnNoRows    bool
+	EmitErrClassifier   bool
+	EmitMock            bool
+	EmitQueriesFile     bool
+	EmitQueryHook       bool
+	EmitStore           bool
+	EmitCheckValidators bool
+
+	// Name of the receiver in methods on Queries
+	Receiver string
+
+	// Name of the function returning a new Queries
+	Constructor string
+
+	// Package clause of the Querier interface file when it is written to
+	// querier_path
+	QuerierPackage string
+
+	// Null types generated when emit_null_types is set
+	NullTypes []GoNullType
+
+	// Constants for CHECK constraints, set when emit_check_constants is set
+	CheckEnums []GoEnum
+
+	// Go expression for the default query timeout, empty when unset
+	QueryTimeout string
+}
+
+// PreparedQueries returns the queries prepared by Prepare. :execmany queries
+// build their SQL on each call, so they can't be prepared.
+func (t tmplCtx) PreparedQueries() []GoQuery {
+	var qs []GoQuery
+	for _, q := range t.GoQueries {
+		if q.Cmd != ":execmany" {
+			qs = append(qs, q)
+		}
+	}
+	return qs
+}
+
+// HasComposites reports whether the file's structs need the composite
+// parsing and encoding helpers
+func (t tmplCtx) HasComposites() bool {
+	for _, s := range t.Structs {
+		if s.Composite {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag returns the quoted struct tag for a field, skipping the JSON tag unless
+// JSON tags are enabled
+func (t tmplCtx) Tag(f GoField) string {
+	tags := map[string]string{}
+	for key, val := range f.Tags {
+		if key == "json:" && !t.EmitJSONTags {
+			continue
+		}
+		tags[key] = val
+	}
+	tag := GoField{Tags: tags}.Tag()
+	if tag == "" {
+		return ""
+	}
+	return t.Q + tag + t.Q
+}
+
+// QueryIdentifiers returns the method and constant names for a query. An
+// unexported method would share its name with the constant, so the constant
+// gets a suffix instead.
+func QueryIdentifiers(name string, pkg PackageSettings) (string, string) {
+	if pkg.EmitUnexported {
+		return LowerTitle(name), LowerTitle(name) + "Query"
+	}
+	return name, LowerTitle(name)
+}
+
+// QueryFieldName returns the name of the Queries field holding the prepared
+// statement of a query. The accessor of an unexported query, e.g. getFooStmt,
+// would clash with the usual name, so the field gets another suffix instead.
+func QueryFieldName(name string, pkg PackageSettings) string {
+	if pkg.EmitUnexported && pkg.EmitStmtAccessors {
+		return LowerTitle(name) + "Prepared"
+	}
+	return LowerTitle(name) + "Stmt"
 }
 
 func LowerTitle(s string) string {
@@ -1097,6 +2706,98 @@ func LowerTitle(s string) string {
 	return string(a)
 }
 
+// durationLiteral renders a duration as a Go expression using the largest
+// unit that divides it evenly
+func durationLiteral(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	} {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.d, unit.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
+
+// checkArrayTypes returns an error for the first array whose element type
+// has no Go mapping. pq.Array can't scan into an []interface{}.
+func checkArrayTypes(structs []GoStruct, queries []GoQuery) error {
+	return checkTypes(structs, queries, "unsupported array element type", func(typ string) bool {
+		return typ == "[]interface{}" || typ == "*[]interface{}"
+	})
+}
+
+// checkUnknownTypes returns an error for the first value whose type has no Go
+// mapping
+func checkUnknownTypes(structs []GoStruct, queries []GoQuery) error {
+	return checkTypes(structs, queries, "unsupported type", func(typ string) bool {
+		return elemType(typ) == "interface{}"
+	})
+}
+
+// checkTypes returns an error, reading "<name>: <msg>", for the first struct
+// field or query value whose type is unsupported
+func checkTypes(structs []GoStruct, queries []GoQuery, msg string, unsupported func(string) bool) error {
+	check := func(name, typ string) error {
+		if unsupported(typ) {
+			return fmt.Errorf("%s: %s", name, msg)
+		}
+		return nil
+	}
+	for _, s := range structs {
+		for _, f := range s.Fields {
+			if err := check(s.Name+"."+f.Name, f.Type); err != nil {
+				return err
+			}
+		}
+	}
+	for _, q := range queries {
+		for _, v := range []GoQueryValue{q.Arg, q.Ret} {
+			switch {
+			case v.isEmpty():
+			case v.Struct != nil:
+				if !v.Emit && !v.Positional {
+					// a model, already checked above
+					continue
+				}
+				for _, f := range v.Struct.Fields {
+					if err := check(q.MethodName+" "+f.Name, f.Type); err != nil {
+						return err
+					}
+				}
+			default:
+				if err := check(q.MethodName+" "+v.Name, v.Typ); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeFileHeader writes the configured build constraint and header comment,
+// which precede the generated code warning in every file
+func writeFileHeader(w io.Writer, pkg PackageSettings) {
+	if pkg.BuildTags != "" {
+		fmt.Fprintf(w, "// +build %s\n\n", pkg.BuildTags)
+	}
+	if pkg.Header != "" {
+		for _, line := range strings.Split(strings.TrimRight(pkg.Header, "\n"), "\n") {
+			fmt.Fprintln(w, strings.TrimRight("// "+line, " "))
+		}
+	}
+}
+
 func Generate(r Generateable, settings GenerateSettings) (map[string]string, error) {
 	funcMap := template.FuncMap{
 		"lowerTitle": LowerTitle,
@@ -1110,14 +2811,48 @@ func Generate(r Generateable, settings GenerateSettings) (map[string]string, err
 	}
 
 	dbFile := template.Must(template.New("table").Funcs(funcMap).Parse(dbTmpl))
+	template.Must(dbFile.Parse(querierIfaceTmpl))
+	querierFile := template.Must(template.New("table").Funcs(funcMap).Parse(querierTmpl))
+	template.Must(querierFile.Parse(querierIfaceTmpl))
 	modelsFile := template.Must(template.New("table").Funcs(funcMap).Parse(modelsTmpl))
 	sqlFile := template.Must(template.New("table").Funcs(funcMap).Parse(sqlTmpl))
+	mockFile := template.Must(template.New("table").Funcs(funcMap).Parse(mockTmpl))
+	queriesFile := template.Must(template.New("table").Funcs(funcMap).Parse(queriesTmpl))
+
+	timeout, err := pkgConfig.queryTimeout()
+	if err != nil {
+		return nil, fmt.Errorf("default_query_timeout: %w", err)
+	}
 
 	tctx := tmplCtx{
 		Settings:            settings,
 		EmitInterface:       pkgConfig.EmitInterface,
+		EmitPing:            pkgConfig.EmitPing,
+		EmitExec:            pkgConfig.EmitExec,
+		EmitJSONValue:       UsesJSONValues(r, settings),
+		EmitNullArray:       UsesNullArrays(r, settings),
+		EmitStringer:        pkgConfig.EmitStringer,
+		EmitEnumJSON:        pkgConfig.EmitEnumJSON,
+		EmitEnumValid:       pkgConfig.EmitEnumValid,
+		EmitDeepCopy:        pkgConfig.EmitDeepCopy,
+		EmitMethodExamples:  pkgConfig.EmitMethodExamples,
+		EmitEmptySlices:     pkgConfig.EmitEmptySlices,
+		EmitErrNotFound:     pkgConfig.EmitErrNotFound,
+		EmitZeroOnNoRows:    pkgConfig.EmitZeroOnNoRows,
+		EmitErrClassifier:   pkgConfig.EmitErrClassifier,
+		EmitMock:            pkgConfig.EmitMock,
+		EmitQueriesFile:     pkgConfig.EmitQueriesFile,
+		EmitQueryHook:       pkgConfig.EmitQueryHook,
+		EmitStore:           pkgConfig.EmitStore,
+		EmitCheckValidators: pkgConfig.EmitCheckValidators,
+		Receiver:            pkgConfig.receiverName(),
+		Constructor:         pkgConfig.constructorName(),
+		QuerierPackage:      pkgConfig.querierPackage(),
+		QueryTimeout:        durationLiteral(timeout),
 		EmitJSONTags:        pkgConfig.EmitJSONTags,
+		EmitDBTags:          pkgConfig.EmitDBTags,
 		EmitPreparedQueries: pkgConfig.EmitPreparedQueries,
+		EmitStmtAccessors:   pkgConfig.EmitStmtAccessors,
 		Q:                   "`",
 		Package:             pkgName,
 		GoQueries:           r.GoQueries(settings),
@@ -1125,11 +2860,29 @@ func Generate(r Generateable, settings GenerateSettings) (map[string]string, err
 		Structs:             r.Structs(settings),
 	}
 
+	if pkgConfig.EmitNullTypes {
+		tctx.NullTypes = goNullTypes
+	}
+	if pkgConfig.EmitCheckConstants {
+		tctx.CheckEnums = r.CheckEnums(settings)
+	}
+	if pkgConfig.StrictArrayTypes {
+		if err := checkArrayTypes(tctx.Structs, tctx.GoQueries); err != nil {
+			return nil, err
+		}
+	}
+	if pkgConfig.StrictTypes {
+		if err := checkUnknownTypes(tctx.Structs, tctx.GoQueries); err != nil {
+			return nil, err
+		}
+	}
+
 	output := map[string]string{}
 
 	execute := func(name string, t *template.Template) error {
 		var b bytes.Buffer
 		w := bufio.NewWriter(&b)
+		writeFileHeader(w, pkgConfig)
 		tctx.SourceName = name
 		err := t.Execute(w, tctx)
 		w.Flush()
@@ -1139,7 +2892,7 @@ func Generate(r Generateable, settings GenerateSettings) (map[string]string, err
 		code, err := format.Source(b.Bytes())
 		if err != nil {
 			fmt.Println(b.String())
-			return fmt.Errorf("source error: %w", err)
+			return fmt.Errorf("%s: generated code is not valid Go: %w", name, err)
 		}
 		if !strings.HasSuffix(name, ".go") {
 			name += ".go"
@@ -1151,8 +2904,42 @@ func Generate(r Generateable, settings GenerateSettings) (map[string]string, err
 	if err := execute("db.go", dbFile); err != nil {
 		return nil, err
 	}
-	if err := execute("models.go", modelsFile); err != nil {
-		return nil, err
+	if name := pkgConfig.querierFile(); name != "" {
+		// The interface lives in another package, so the types it shares
+		// with the generated package need qualifying
+		queries := tctx.GoQueries
+		tctx.GoQueries = qualifyQueries(queries, pkgName, localTypes(tctx))
+		err := execute(name, querierFile)
+		tctx.GoQueries = queries
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pkgConfig.EmitMock {
+		if err := execute("mock.go", mockFile); err != nil {
+			return nil, err
+		}
+	}
+	if pkgConfig.EmitQueriesFile {
+		if err := execute("queries_sql.go", queriesFile); err != nil {
+			return nil, err
+		}
+	}
+	if pkgConfig.EmitEnumsFile {
+		// Enums and structs share a template; render each into its own file
+		enums, checks := tctx.Enums, tctx.CheckEnums
+		tctx.Enums, tctx.CheckEnums = nil, nil
+		if err := execute("models.go", modelsFile); err != nil {
+			return nil, err
+		}
+		tctx.Enums, tctx.CheckEnums, tctx.Structs, tctx.NullTypes = enums, checks, nil, nil
+		if err := execute("enums.go", modelsFile); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := execute("models.go", modelsFile); err != nil {
+			return nil, err
+		}
 	}
 
 	files := map[string]struct{}{}
@@ -1165,5 +2952,152 @@ func Generate(r Generateable, settings GenerateSettings) (map[string]string, err
 			return nil, err
 		}
 	}
+	if pkgConfig.EmitSingleFile {
+		querier, moved := output[pkgConfig.querierFile()]
+		delete(output, pkgConfig.querierFile())
+		code, err := combineFiles(output, pkgConfig, pkgName)
+		if err != nil {
+			return nil, err
+		}
+		combined := map[string]string{"db.go": code}
+		if moved {
+			combined[pkgConfig.querierFile()] = querier
+		}
+		return combined, nil
+	}
 	return output, nil
 }
+
+// localTypes returns the names of the types declared in the generated
+// package
+func localTypes(t tmplCtx) map[string]struct{} {
+	local := map[string]struct{}{}
+	for _, s := range t.Structs {
+		local[s.Name] = struct{}{}
+	}
+	for _, e := range t.Enums {
+		local[e.Name] = struct{}{}
+	}
+	for _, n := range t.NullTypes {
+		local[n.Name] = struct{}{}
+	}
+	for _, q := range t.GoQueries {
+		for _, v := range []GoQueryValue{q.Arg, q.Ret} {
+			if v.Struct != nil && !v.Positional {
+				local[v.Struct.Name] = struct{}{}
+			}
+		}
+	}
+	return local
+}
+
+// qualifyQueries returns copies of queries whose values refer to the types in
+// local through the named package, e.g. Author becomes db.Author
+func qualifyQueries(queries []GoQuery, pkg string, local map[string]struct{}) []GoQuery {
+	qualify := func(typ string) string {
+		name := strings.TrimLeft(typ, "*[]")
+		if _, ok := local[name]; !ok {
+			return typ
+		}
+		return typ[:len(typ)-len(name)] + pkg + "." + name
+	}
+	value := func(v GoQueryValue) GoQueryValue {
+		switch {
+		case v.isEmpty():
+		case v.Positional:
+			s := *v.Struct
+			s.Fields = make([]GoField, len(v.Struct.Fields))
+			for i, f := range v.Struct.Fields {
+				f.Type = qualify(f.Type)
+				s.Fields[i] = f
+			}
+			v.Struct = &s
+		default:
+			v.Typ = qualify(v.Type())
+		}
+		return v
+	}
+	out := make([]GoQuery, len(queries))
+	for i, q := range queries {
+		q.Arg = value(q.Arg)
+		q.Ret = value(q.Ret)
+		out[i] = q
+	}
+	return out
+}
+
+// combineFiles merges the generated files into one, keeping the boilerplate
+// and models ahead of the queries
+func combineFiles(output map[string]string, pkg PackageSettings, pkgName string) (string, error) {
+	rank := map[string]int{"db.go": 1, "models.go": 2, "enums.go": 3, "mock.go": 4, "queries_sql.go": 5}
+	names := make([]string, 0, len(output))
+	for name := range output {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := rank[names[i]], rank[names[j]]
+		if ri == 0 {
+			ri = len(rank) + 1
+		}
+		if rj == 0 {
+			rj = len(rank) + 1
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+
+	std := map[string]struct{}{}
+	pkgs := map[string]struct{}{}
+	var body bytes.Buffer
+	for _, name := range names {
+		src := output[name]
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+			if strings.Contains(strings.Split(path, "/")[0], ".") {
+				pkgs[path] = struct{}{}
+			} else {
+				std[path] = struct{}{}
+			}
+		}
+		end := f.Name.End()
+		if len(f.Decls) > 0 {
+			end = f.Decls[len(f.Decls)-1].End()
+		}
+		if _, ok := rank[name]; !ok {
+			fmt.Fprintf(&body, "\n// source: %s\n", strings.TrimSuffix(name, ".go"))
+		}
+		body.WriteString(src[fset.Position(end).Offset:])
+	}
+
+	var b bytes.Buffer
+	writeFileHeader(&b, pkg)
+	fmt.Fprintf(&b, "// Code generated by sqlc. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	for _, group := range []map[string]struct{}{std, pkgs} {
+		paths := make([]string, 0, len(group))
+		for path := range group {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			fmt.Fprintf(&b, "%q\n", path)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(")\n")
+	b.Write(body.Bytes())
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("db.go: combined code is not valid Go: %w", err)
+	}
+	return string(code), nil
+}
@@ -1,11 +1,18 @@
 package dinosql
 
 import (
+	"go/format"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/kyleconroy/sqlc/internal/pg"
+
+	pgquery "github.com/lfittl/pg_query_go"
 )
 
 func TestColumnsToStruct(t *testing.T) {
@@ -94,6 +101,147 @@ func TestColumnsToStruct(t *testing.T) {
 	}
 }
 
+func TestColumnsToStructFieldName(t *testing.T) {
+	cols := []pg.Column{
+		{
+			Name:     "id",
+			DataType: "text",
+			NotNull:  true,
+			Table:    pg.FQN{Schema: "public", Rel: "foo"},
+		},
+		{
+			Name:     "name",
+			DataType: "text",
+			NotNull:  true,
+			Table:    pg.FQN{Schema: "public", Rel: "foo"},
+		},
+	}
+
+	o := Override{
+		GoFieldName: "Identifier",
+		Column:      "foo.id",
+	}
+	if err := o.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgName := "test_field_name"
+
+	r := Result{
+		packageName: pkgName,
+	}
+	mockSettings.PackageMap[pkgName] = PackageSettings{
+		Overrides: []Override{o},
+	}
+
+	actual := r.columnsToStruct("Foo", cols, mockSettings)
+	expected := &GoStruct{
+		Name: "Foo",
+		Fields: []GoField{
+			{Name: "Identifier", Type: "string", Tags: map[string]string{"json:": "id"}},
+			{Name: "Name", Type: "string", Tags: map[string]string{"json:": "name"}},
+		},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("struct mismatch: \n%s", diff)
+	}
+}
+
+func TestColumnsToStructTags(t *testing.T) {
+	cols := []pg.Column{
+		{
+			Name:     "id",
+			DataType: "text",
+			NotNull:  true,
+			Table:    pg.FQN{Schema: "public", Rel: "foo"},
+		},
+		{
+			Name:     "name",
+			DataType: "text",
+			NotNull:  true,
+			Table:    pg.FQN{Schema: "public", Rel: "foo"},
+		},
+	}
+
+	o := Override{
+		GoStructTags: map[string]string{"validate": "required"},
+		Column:       "foo.name",
+	}
+	if err := o.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgName := "test_struct_tags"
+
+	r := Result{
+		packageName: pkgName,
+	}
+	mockSettings.PackageMap[pkgName] = PackageSettings{
+		Overrides: []Override{o},
+	}
+
+	actual := r.columnsToStruct("Foo", cols, mockSettings)
+	expected := &GoStruct{
+		Name: "Foo",
+		Fields: []GoField{
+			{Name: "ID", Type: "string", Tags: map[string]string{"json:": "id"}},
+			{Name: "Name", Type: "string", Tags: map[string]string{"json:": "name", "validate:": "required"}},
+		},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("struct mismatch: \n%s", diff)
+	}
+}
+
+func TestColumnsToStructNullable(t *testing.T) {
+	cols := []pg.Column{
+		{
+			Name:     "name",
+			DataType: "text",
+			NotNull:  true,
+			Table:    pg.FQN{Schema: "public", Rel: "foo"},
+		},
+		{
+			Name:     "bio",
+			DataType: "text",
+			NotNull:  false,
+			Table:    pg.FQN{Schema: "public", Rel: "foo"},
+		},
+	}
+
+	nullable, notNull := true, false
+	overrides := []Override{
+		{Column: "foo.name", Nullable: &nullable},
+		{Column: "foo.bio", Nullable: &notNull},
+	}
+	for i := range overrides {
+		if err := overrides[i].Parse(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgName := "test_nullable"
+
+	r := Result{
+		packageName: pkgName,
+	}
+	mockSettings.PackageMap[pkgName] = PackageSettings{
+		Overrides: overrides,
+	}
+
+	actual := r.columnsToStruct("Foo", cols, mockSettings)
+	expected := &GoStruct{
+		Name: "Foo",
+		Fields: []GoField{
+			{Name: "Name", Type: "sql.NullString", Tags: map[string]string{"json:": "name"}},
+			{Name: "Bio", Type: "string", Tags: map[string]string{"json:": "bio"}},
+		},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("struct mismatch: \n%s", diff)
+	}
+}
+
 var mockSettings GenerateSettings
 
 func init() {
@@ -105,12 +253,12 @@ func init() {
 			},
 			PackageSettings{
 				Name:                "prepared",
-				Queries:             filepath.Join("testdata", "ondeck", "query"),
+				Queries:             Paths{filepath.Join("testdata", "ondeck", "query")},
 				EmitPreparedQueries: true,
 			},
 			PackageSettings{
 				Name:         "ondeck",
-				Queries:      filepath.Join("testdata", "ondeck", "query"),
+				Queries:      Paths{filepath.Join("testdata", "ondeck", "query")},
 				EmitJSONTags: true,
 			},
 		},
@@ -128,19 +276,64 @@ func TestInnerType(t *testing.T) {
 		"int":                "int32",
 		"pg_catalog.int4":    "int32",
 		"pg_catalog.numeric": "string",
+		"smallint":           "int16",
+		"pg_catalog.int2":    "int16",
 
 		// Character Types
 		// https://www.postgresql.org/docs/current/datatype-character.html
-		"string": "string",
+		"string":             "string",
+		"text":               "string",
+		"pg_catalog.text":    "string",
+		"pg_catalog.varchar": "string",
+		"bpchar":             "string",
+		"pg_catalog.bpchar":  "string",
+
+		// Boolean Type
+		// https://www.postgresql.org/docs/current/datatype-boolean.html
+		"bool":            "bool",
+		"boolean":         "bool",
+		"pg_catalog.bool": "bool",
 
 		// Date/Time Types
 		// https://www.postgresql.org/docs/current/datatype-datetime.html
 		"date":                   "time.Time",
 		"pg_catalog.time":        "time.Time",
 		"pg_catalog.timetz":      "time.Time",
+		"timetz":                 "time.Time",
 		"pg_catalog.timestamp":   "time.Time",
 		"pg_catalog.timestamptz": "time.Time",
 		"timestamptz":            "time.Time",
+
+		// JSON Types
+		// https://www.postgresql.org/docs/current/datatype-json.html
+		"json":             "json.RawMessage",
+		"jsonb":            "json.RawMessage",
+		"pg_catalog.json":  "json.RawMessage",
+		"pg_catalog.jsonb": "json.RawMessage",
+
+		// Range Types
+		// https://www.postgresql.org/docs/current/rangetypes.html
+		"int4range":            "string",
+		"int8range":            "string",
+		"numrange":             "string",
+		"tsrange":              "string",
+		"tstzrange":            "string",
+		"daterange":            "string",
+		"pg_catalog.int4range": "string",
+
+		// Monetary Types
+		// https://www.postgresql.org/docs/current/datatype-money.html
+		"money":            "string",
+		"pg_catalog.money": "string",
+
+		// UUID Type
+		// https://www.postgresql.org/docs/current/datatype-uuid.html
+		"uuid": "uuid.UUID",
+
+		// Object Identifier Types
+		// https://www.postgresql.org/docs/current/datatype-oid.html
+		"oid":            "uint32",
+		"pg_catalog.oid": "uint32",
 	}
 	for k, v := range types {
 		dbType := k
@@ -163,19 +356,56 @@ func TestNullInnerType(t *testing.T) {
 		"int":                "sql.NullInt32",
 		"pg_catalog.int4":    "sql.NullInt32",
 		"pg_catalog.numeric": "sql.NullString",
+		"smallint":           "sql.NullInt32",
+		"int2":               "sql.NullInt32",
+		"pg_catalog.int2":    "sql.NullInt32",
+		"real":               "sql.NullFloat64",
+		"pg_catalog.float4":  "sql.NullFloat64",
 
 		// Character Types
 		// https://www.postgresql.org/docs/current/datatype-character.html
-		"string": "sql.NullString",
+		"string":            "sql.NullString",
+		"bpchar":            "sql.NullString",
+		"pg_catalog.bpchar": "sql.NullString",
+
+		// Boolean Type
+		// https://www.postgresql.org/docs/current/datatype-boolean.html
+		"bool":            "sql.NullBool",
+		"boolean":         "sql.NullBool",
+		"pg_catalog.bool": "sql.NullBool",
 
 		// Date/Time Types
 		// https://www.postgresql.org/docs/current/datatype-datetime.html
 		"date":                   "sql.NullTime",
 		"pg_catalog.time":        "sql.NullTime",
 		"pg_catalog.timetz":      "sql.NullTime",
+		"timetz":                 "sql.NullTime",
 		"pg_catalog.timestamp":   "sql.NullTime",
 		"pg_catalog.timestamptz": "sql.NullTime",
 		"timestamptz":            "sql.NullTime",
+
+		// Binary Data Types
+		// https://www.postgresql.org/docs/current/datatype-binary.html
+		"bytea":            "[]byte",
+		"pg_catalog.bytea": "[]byte",
+
+		// Range Types
+		// https://www.postgresql.org/docs/current/rangetypes.html
+		"int4range": "sql.NullString",
+		"tstzrange": "sql.NullString",
+
+		// Monetary Types
+		// https://www.postgresql.org/docs/current/datatype-money.html
+		"money": "sql.NullString",
+
+		// UUID Type
+		// https://www.postgresql.org/docs/current/datatype-uuid.html
+		"uuid": "uuid.UUID",
+
+		// Object Identifier Types
+		// https://www.postgresql.org/docs/current/datatype-oid.html
+		"oid":            "sql.NullInt64",
+		"pg_catalog.oid": "sql.NullInt64",
 	}
 	for k, v := range types {
 		dbType := k
@@ -189,6 +419,73 @@ func TestNullInnerType(t *testing.T) {
 	}
 }
 
+func TestByteaStringOverride(t *testing.T) {
+	o := Override{
+		GoType:       "string",
+		PostgresType: "bytea",
+	}
+	if err := o.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgName := "test_bytea_string"
+
+	r := Result{packageName: pkgName}
+	mockSettings.PackageMap[pkgName] = PackageSettings{
+		Overrides: []Override{o},
+	}
+
+	for _, tc := range []struct {
+		col    pg.Column
+		goType string
+	}{
+		{pg.Column{DataType: "bytea", NotNull: true}, "string"},
+		{pg.Column{DataType: "bytea", NotNull: true, IsArray: true}, "[]string"},
+		{pg.Column{DataType: "bytea", IsArray: true}, "[]string"},
+	} {
+		col := tc.col
+		goType := tc.goType
+		t.Run(goType, func(t *testing.T) {
+			if actual := r.goType(col, mockSettings); actual != goType {
+				t.Errorf("expected Go type for %s to be %s, not %s", col.DataType, goType, actual)
+			}
+		})
+	}
+}
+
+func TestGoIntInnerType(t *testing.T) {
+	pkgName := "test_go_int"
+
+	r := Result{packageName: pkgName}
+	mockSettings.PackageMap[pkgName] = PackageSettings{
+		EmitGoInt: true,
+	}
+
+	types := map[string]string{
+		"integer":            "int",
+		"pg_catalog.int4":    "int",
+		"pg_catalog.int8":    "int",
+		"pg_catalog.int2":    "int",
+		"serial":             "int",
+		"pg_catalog.numeric": "string",
+	}
+	for k, v := range types {
+		dbType := k
+		goType := v
+		t.Run(k+"-"+v, func(t *testing.T) {
+			col := pg.Column{DataType: dbType, NotNull: true}
+			if goType != r.goType(col, mockSettings) {
+				t.Errorf("expected Go type for %s to be %s, not %s", dbType, goType, r.goType(col, mockSettings))
+			}
+		})
+	}
+
+	col := pg.Column{DataType: "integer"}
+	if actual := r.goType(col, mockSettings); actual != "sql.NullInt64" {
+		t.Errorf("expected Go type for nullable integer to be sql.NullInt64, not %s", actual)
+	}
+}
+
 func TestEnumValueName(t *testing.T) {
 	values := map[string]string{
 		// Valid separators
@@ -212,3 +509,2277 @@ func TestEnumValueName(t *testing.T) {
 		})
 	}
 }
+
+// generatePackage runs the full pipeline, from schema and queries to Go
+// source, for a single package.
+func generatePackage(t *testing.T, schema, queries string, pkg PackageSettings) map[string]string {
+	t.Helper()
+
+	r, settings := parsePackage(t, schema, queries, pkg)
+	output, err := Generate(r, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+// parsePackage parses the schema and queries for a single package, ready to
+// pass to Generate.
+func parsePackage(t *testing.T, schema, queries string, pkg PackageSettings) (Result, GenerateSettings) {
+	t.Helper()
+
+	c := pg.NewCatalog()
+	tree, err := pgquery.Parse(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updateCatalog(&c, tree); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err = pgquery.Parse(queries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var qs []*Query
+	for _, stmt := range tree.Statements {
+		q, err := parseQuery(c, stmt, queries)
+		if err == errUnsupportedStatementType || err == errSkippedQuery {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.Filename = "query.sql"
+		qs = append(qs, q)
+	}
+
+	if pkg.Name == "" {
+		pkg.Name = "db"
+	}
+	for i := range pkg.Overrides {
+		if err := pkg.Overrides[i].Parse(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settings := GenerateSettings{
+		Version:  "1",
+		Packages: []PackageSettings{pkg},
+	}
+	if err := settings.PopulatePkgMap(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := Result{
+		Catalog:     c,
+		Queries:     qs,
+		packageName: pkg.Name,
+	}
+	return r, settings
+}
+
+const fooSchema = `
+CREATE TABLE foo (
+    id   serial primary key,
+    name text   not null,
+    bio  text
+);
+`
+
+func TestPreparedCloseErrorNamesQuery(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`, PackageSettings{EmitPreparedQueries: true})
+
+	for _, expected := range []string{
+		`err = fmt.Errorf("error closing query GetFoo: %w", cerr)`,
+		`err = fmt.Errorf("%w; error closing query GetFoo: %v", err, cerr)`,
+	} {
+		if !strings.Contains(output["db.go"], expected) {
+			t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+		}
+	}
+}
+
+func TestPreparedStmtAccessors(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	expected := "func (q *Queries) GetFooStmt() *sql.Stmt {\n\treturn q.getFooStmt\n}"
+
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitPreparedQueries: true, EmitStmtAccessors: true})
+	if !strings.Contains(output["db.go"], expected) {
+		t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{EmitPreparedQueries: true})
+	if strings.Contains(output["db.go"], "GetFooStmt()") {
+		t.Errorf("db.go contains a statement accessor without emit_stmt_accessors:\n%s", output["db.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{EmitStmtAccessors: true})
+	if strings.Contains(output["db.go"], "GetFooStmt()") {
+		t.Errorf("db.go contains a statement accessor without emit_prepared_queries:\n%s", output["db.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{EmitPreparedQueries: true, EmitStmtAccessors: true, EmitUnexported: true})
+	if expected := "func (q *Queries) getFooStmt() *sql.Stmt {\n\treturn q.getFooPrepared\n}"; !strings.Contains(output["db.go"], expected) {
+		t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+	}
+}
+
+func TestStructSuffixes(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: UpdateFoo :one
+UPDATE foo SET name = $1 WHERE id = $2 RETURNING id, name;
+`, PackageSettings{ParamsStructSuffix: "Args", RowStructSuffix: "Result"})
+
+	for _, expected := range []string{
+		"type UpdateFooArgs struct {",
+		"type UpdateFooResult struct {",
+		"func (q *Queries) UpdateFoo(ctx context.Context, arg UpdateFooArgs) (UpdateFooResult, error) {",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+func TestExcludeTables(t *testing.T) {
+	output := generatePackage(t, fooSchema+`
+CREATE TABLE schema_migrations (version bigint primary key);
+`, `
+-- name: ListMigrations :many
+SELECT * FROM schema_migrations;
+`, PackageSettings{ExcludeTables: []string{"schema_migrations"}})
+
+	if strings.Contains(output["models.go"], "SchemaMigration") {
+		t.Errorf("models.go contains a struct for an excluded table:\n%s", output["models.go"])
+	}
+	if !strings.Contains(output["models.go"], "type Foo struct {") {
+		t.Errorf("models.go does not contain a struct for foo:\n%s", output["models.go"])
+	}
+	expected := "func (q *Queries) ListMigrations(ctx context.Context) ([]int64, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestOmitUnusedStructs(t *testing.T) {
+	schema := `
+CREATE TABLE foo (id int not null);
+CREATE TABLE bar (id int not null);
+`
+	queries := `
+-- name: ListFoos :many
+SELECT * FROM foo;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{})
+	if !strings.Contains(output["models.go"], "type Bar struct") {
+		t.Errorf("models.go does not contain Bar:\n%s", output["models.go"])
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{OmitUnusedStructs: true})
+	if strings.Contains(output["models.go"], "type Bar struct") {
+		t.Errorf("models.go contains unused Bar:\n%s", output["models.go"])
+	}
+	if !strings.Contains(output["models.go"], "type Foo struct") {
+		t.Errorf("models.go does not contain Foo:\n%s", output["models.go"])
+	}
+}
+
+func TestOmitUnusedStructsKeepsEnums(t *testing.T) {
+	schema := `
+CREATE TYPE status AS ENUM ('open', 'closed');
+CREATE TABLE foo (id int not null);
+`
+	queries := `
+-- name: ListFoos :many
+SELECT * FROM foo;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{OmitUnusedStructs: true})
+	for _, expected := range []string{
+		"type Status string",
+		`StatusOpen   Status = "open"`,
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+}
+
+func TestNullableArrays(t *testing.T) {
+	schema := `CREATE TABLE foo (id serial primary key, tags text[], scores int[] not null);`
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: UpdateTags :exec
+UPDATE foo SET tags = $2 WHERE id = $1;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{EmitNullableArrays: true})
+	for name, expected := range map[string][]string{
+		"models.go": {
+			"Tags   *[]string",
+			"Scores []int32",
+		},
+		"query.sql.go": {
+			"err := row.Scan(&i.ID, nullArray{&i.Tags}, pq.Array(&i.Scores))",
+			"_, err := q.db.ExecContext(ctx, updateTags, arg.ID, nullArray{arg.Tags})",
+		},
+		"db.go": {
+			"type nullArray struct {",
+		},
+	} {
+		for _, e := range expected {
+			if !strings.Contains(output[name], e) {
+				t.Errorf("%s does not contain %q:\n%s", name, e, output[name])
+			}
+		}
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{})
+	if !strings.Contains(output["models.go"], "Tags   []string") {
+		t.Errorf("models.go does not use a slice without emit_nullable_arrays:\n%s", output["models.go"])
+	}
+	if strings.Contains(output["db.go"], "nullArray") {
+		t.Errorf("db.go contains the nullArray helper without emit_nullable_arrays:\n%s", output["db.go"])
+	}
+}
+
+func TestAnyArrayParameter(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: ListFoos :many
+SELECT * FROM foo WHERE id = ANY($1);
+`, PackageSettings{})
+
+	expected := "func (q *Queries) ListFoos(ctx context.Context, id []int32) ([]Foo, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestJSONBCastParameter(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (id int not null, data jsonb not null);`, `
+-- name: ListFoos :many
+SELECT id FROM foo WHERE data @> $1::jsonb;
+`, PackageSettings{})
+
+	expected := "func (q *Queries) ListFoos(ctx context.Context, dollar_1 json.RawMessage) ([]int32, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestJSONBTextOperator(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (id int not null, data jsonb not null);`, `
+-- name: ListFooNames :many
+SELECT id, data->>'name' AS name FROM foo;
+`, PackageSettings{})
+
+	expected := "Name sql.NullString\n"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestUnnestInsertParameter(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: CreateFoos :exec
+INSERT INTO foo (name) SELECT unnest($1::text[]);
+`, PackageSettings{})
+
+	expected := "func (q *Queries) CreateFoos(ctx context.Context, name []string) error {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestArgName(t *testing.T) {
+	for input, expected := range map[string]string{
+		"name":      "name",
+		"author_id": "authorID",
+		"ID":        "id",
+		"Name":      "name",
+		"userId":    "userId",
+		"user-id":   "userID",
+		"type":      "type_",
+	} {
+		if actual := argName(input); actual != expected {
+			t.Errorf("argName(%q) = %q, expected %q", input, actual, expected)
+		}
+	}
+}
+
+func TestKeywordParameterName(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (id int not null, type text not null, "func" text not null);`, `
+-- name: ListFoos :many
+SELECT id FROM foo WHERE type = $1;
+
+-- name: ListFoosByFunc :many
+SELECT id FROM foo WHERE "func" = $1 AND type = $2;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"func (q *Queries) ListFoos(ctx context.Context, type_ string) ([]int32, error) {",
+		"q.db.QueryContext(ctx, listFoos, type_)",
+		"Func string\n",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+func TestFunctionArgumentParameter(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE events (id int not null, created_at timestamptz not null);`, `
+-- name: ListEventsOnDay :many
+SELECT id FROM events WHERE date_trunc('day', created_at) = date_trunc('day', $1);
+`, PackageSettings{})
+
+	expected := "func (q *Queries) ListEventsOnDay(ctx context.Context, source time.Time) ([]int32, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestDistinctOnReusesTableStruct(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: ListFoos :many
+SELECT DISTINCT ON (name) * FROM foo ORDER BY name, id;
+`, PackageSettings{})
+
+	expected := "func (q *Queries) ListFoos(ctx context.Context) ([]Foo, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestReturningReusesTableStruct(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: UpdateFoo :one
+UPDATE foo SET name = $2 WHERE id = $1 RETURNING *;
+
+-- name: DeleteFoos :many
+DELETE FROM foo WHERE name = $1 RETURNING *;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"func (q *Queries) UpdateFoo(ctx context.Context, arg UpdateFooParams) (Foo, error) {",
+		"func (q *Queries) DeleteFoos(ctx context.Context, name string) ([]Foo, error) {",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+	if strings.Contains(output["query.sql.go"], "Row struct") {
+		t.Errorf("query.sql.go generates a row struct for RETURNING *:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestGroupByRowStruct(t *testing.T) {
+	output := generatePackage(t, `
+CREATE TABLE foo (id serial primary key, name text not null);
+CREATE TABLE bar (id serial primary key, foo_id int not null references foo(id), label text);
+`, `
+-- name: CountBarsByFoo :many
+SELECT foo_id, count(*) FROM bar GROUP BY foo_id;
+
+-- name: CountBarsByName :many
+SELECT f.name, count(b.id) AS bars
+FROM foo f
+JOIN bar b ON b.foo_id = f.id
+GROUP BY f.name;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"type CountBarsByFooRow struct {\n\tFooID int32\n\tCount int64\n}",
+		"func (q *Queries) CountBarsByFoo(ctx context.Context) ([]CountBarsByFooRow, error) {",
+		"type CountBarsByNameRow struct {\n\tName string\n\tBars int64\n}",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+func TestExistsReturnsBool(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: FooExists :one
+SELECT EXISTS(SELECT 1 FROM foo WHERE id = $1);
+`, PackageSettings{})
+
+	expected := "func (q *Queries) FooExists(ctx context.Context, id int32) (bool, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestReusedParameter(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: SearchFoos :many
+SELECT * FROM foo WHERE name = $1 OR bio = $1;
+
+-- name: RenameFoo :one
+UPDATE foo SET name = $1 WHERE name <> $1 AND id = $2 RETURNING id;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"func (q *Queries) SearchFoos(ctx context.Context, name string) ([]Foo, error) {",
+		"q.db.QueryContext(ctx, searchFoos, name)",
+		"type RenameFooParams struct {\n\tName string\n\tID   int32\n}",
+		"q.db.QueryRowContext(ctx, renameFoo, arg.Name, arg.ID)",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+const moodSchema = `
+CREATE TYPE mood AS ENUM ('happy', 'sad');
+
+CREATE TABLE person (
+    name text not null,
+    mood mood not null
+);
+`
+
+func TestEmitEnumsFile(t *testing.T) {
+	queries := `
+-- name: ListPeople :many
+SELECT * FROM person;
+`
+	output := generatePackage(t, moodSchema, queries, PackageSettings{EmitEnumsFile: true})
+
+	constant := `MoodHappy Mood = "happy"`
+	if !strings.Contains(output["enums.go"], constant) {
+		t.Errorf("enums.go does not contain %q:\n%s", constant, output["enums.go"])
+	}
+	if strings.Contains(output["models.go"], constant) {
+		t.Errorf("models.go contains %q:\n%s", constant, output["models.go"])
+	}
+	if !strings.Contains(output["models.go"], "type Person struct") {
+		t.Errorf("models.go does not contain the Person struct:\n%s", output["models.go"])
+	}
+
+	output = generatePackage(t, moodSchema, queries, PackageSettings{})
+	if _, ok := output["enums.go"]; ok {
+		t.Errorf("enums.go generated without emit_enums_file")
+	}
+}
+
+func TestEnumValid(t *testing.T) {
+	queries := `
+-- name: ListPeople :many
+SELECT * FROM person;
+`
+	output := generatePackage(t, moodSchema, queries, PackageSettings{})
+	if strings.Contains(output["models.go"], "Valid()") {
+		t.Errorf("models.go contains a Valid method without emit_enum_valid:\n%s", output["models.go"])
+	}
+
+	output = generatePackage(t, moodSchema, queries, PackageSettings{EmitEnumValid: true, EmitEnumJSON: true})
+
+	testGeneratedPackage(t, output, `package db
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	if !MoodHappy.Valid() {
+		t.Errorf("%q is not valid", MoodHappy)
+	}
+	if Mood("angry").Valid() {
+		t.Errorf("%q is valid", "angry")
+	}
+}
+`)
+}
+
+func TestEmitCheckConstants(t *testing.T) {
+	schema := `CREATE TABLE orders (id int not null, status text not null CHECK (status IN ('pending', 'shipped')));`
+	queries := `
+-- name: ListOrders :many
+SELECT * FROM orders;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{})
+	if strings.Contains(output["models.go"], "OrderStatusPending") {
+		t.Errorf("models.go contains CHECK constants without emit_check_constants:\n%s", output["models.go"])
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{EmitCheckConstants: true})
+	expected := `OrderStatusPending = "pending"`
+	if !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+	if strings.Contains(output["models.go"], "func ValidOrderStatus") {
+		t.Errorf("models.go contains a validator without emit_check_validators:\n%s", output["models.go"])
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{EmitCheckConstants: true, EmitCheckValidators: true})
+}
+
+func TestCheckConstantsEscaping(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE orders (id int not null, status text not null CHECK (status IN ('on "hold"', 'c:\d')));`, `
+-- name: ListOrders :many
+SELECT * FROM orders;
+`, PackageSettings{EmitCheckConstants: true})
+
+	for _, expected := range []string{
+		`OrderStatusOnhold = "on \"hold\""`,
+		`OrderStatusCD     = "c:\\d"`,
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+}
+
+func TestEmitSingleFile(t *testing.T) {
+	queries := `
+-- name: ListPeople :many
+SELECT * FROM person WHERE mood = $1;
+
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	schema := moodSchema + fooSchema
+	output := generatePackage(t, schema, queries, PackageSettings{EmitSingleFile: true, EmitEnumsFile: true, Header: "Copyright"})
+	if len(output) != 1 {
+		t.Fatalf("expected a single file, got %d", len(output))
+	}
+	code := output["db.go"]
+	for _, expected := range []string{
+		"// Copyright\n// Code generated by sqlc. DO NOT EDIT.\n\npackage db\n",
+		"func New(db DBTX) *Queries {",
+		`MoodHappy Mood = "happy"`,
+		"type Person struct {",
+		"// source: query.sql\n",
+		"func (q *Queries) ListPeople(ctx context.Context, mood Mood) ([]Person, error) {",
+		"func (q *Queries) GetFoo(ctx context.Context, id int32) (Foo, error) {",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("db.go does not contain %q:\n%s", expected, code)
+		}
+	}
+	if n := strings.Count(code, "package db"); n != 1 {
+		t.Errorf("db.go contains %d package clauses:\n%s", n, code)
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{})
+	if len(output) == 1 {
+		t.Errorf("generated a single file without emit_single_file")
+	}
+}
+
+func TestReceiverName(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+
+-- name: ListFoos :many
+SELECT * FROM foo WHERE name = $1 AND bio = $2;
+
+-- name: UpdateFoo :execrows
+UPDATE foo SET name = $2 WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{ReceiverName: "queries", EmitPreparedQueries: true, EmitPing: true})
+	for name, expected := range map[string][]string{
+		"db.go": {
+			"func (queries *Queries) Close() error {",
+			"func (queries *Queries) WithTx(tx *sql.Tx) *Queries {",
+			"func (queries *Queries) Ping(ctx context.Context) error {",
+		},
+		"query.sql.go": {
+			"func (queries *Queries) GetFoo(ctx context.Context, id int32) (Foo, error) {",
+			"row := queries.queryRow(ctx, queries.getFooStmt, getFoo, id)",
+			"func (queries *Queries) DeleteFoo(ctx context.Context, id int32) error {",
+		},
+	} {
+		for _, e := range expected {
+			if !strings.Contains(output[name], e) {
+				t.Errorf("%s does not contain %q:\n%s", name, e, output[name])
+			}
+		}
+		if strings.Contains(output[name], "(q *Queries)") {
+			t.Errorf("%s contains the default receiver:\n%s", name, output[name])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if !strings.Contains(output["query.sql.go"], "func (q *Queries) GetFoo(") {
+		t.Errorf("query.sql.go does not use the default receiver:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestConstructorName(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{ConstructorName: "NewQueries", EmitStore: true})
+	for _, expected := range []string{
+		"func NewQueries(db DBTX) *Queries {",
+		"return &Store{Queries: NewQueries(db), db: db}",
+	} {
+		if !strings.Contains(output["db.go"], expected) {
+			t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+		}
+	}
+	if strings.Contains(output["db.go"], "func New(") {
+		t.Errorf("db.go contains the default constructor:\n%s", output["db.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if !strings.Contains(output["db.go"], "func New(db DBTX) *Queries {") {
+		t.Errorf("db.go does not use the default constructor:\n%s", output["db.go"])
+	}
+}
+
+func TestQuerierPath(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: ListFoos :many
+SELECT id, name FROM foo;
+
+-- name: UpdateFoo :exec
+UPDATE foo SET name = $2, bio = $3 WHERE id = $1;
+`
+	pkg := PackageSettings{
+		EmitInterface: true,
+		EmitMock:      true,
+		QuerierPath:   "../ports",
+		QuerierImport: "example.com/app/internal/db",
+	}
+	output := generatePackage(t, fooSchema, queries, pkg)
+	querier, ok := output["../ports/querier.go"]
+	if !ok {
+		t.Fatalf("no querier file in %d generated files", len(output))
+	}
+	for _, expected := range []string{
+		"package ports\n",
+		`"example.com/app/internal/db"`,
+		"GetFoo(ctx context.Context, id int32) (db.Foo, error)",
+		"ListFoos(ctx context.Context) ([]db.ListFoosRow, error)",
+		"UpdateFoo(ctx context.Context, arg db.UpdateFooParams) error",
+		"var _ Querier = (*db.Queries)(nil)",
+	} {
+		if !strings.Contains(querier, expected) {
+			t.Errorf("querier.go does not contain %q:\n%s", expected, querier)
+		}
+	}
+	for _, name := range []string{"db.go", "mock.go"} {
+		if strings.Contains(output[name], "Querier = ") || strings.Contains(output[name], "type Querier interface") {
+			t.Errorf("%s refers to the moved interface:\n%s", name, output[name])
+		}
+	}
+
+	pkg.EmitSingleFile = true
+	output = generatePackage(t, fooSchema, queries, pkg)
+	if _, ok := output["../ports/querier.go"]; !ok || len(output) != 2 {
+		t.Errorf("single file output is not db.go and the querier file: %d files", len(output))
+	}
+}
+
+func TestInterfaceImports(t *testing.T) {
+	schema := `CREATE TABLE events (id serial primary key, created_at timestamptz not null);`
+	queries := `
+-- name: ListEventsSince :many
+SELECT id FROM events WHERE created_at > $1;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{EmitInterface: true})
+	if !strings.Contains(output["db.go"], `"time"`) {
+		t.Errorf("db.go does not import time:\n%s", output["db.go"])
+	}
+}
+
+func TestEmitMethodExamples(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+-- GetFoo looks up a foo by ID
+SELECT * FROM foo WHERE id = $1;
+
+-- name: ListFoos :many
+SELECT * FROM foo;
+
+-- name: UpdateFoo :exec
+UPDATE foo SET name = $2, bio = $3 WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitMethodExamples: true})
+	for _, expected := range []string{
+		"// GetFoo looks up a foo by ID\n//\n// Example:\n//\n//\trow, err := q.GetFoo(ctx, id)\nfunc (q *Queries) GetFoo(",
+		"// Example:\n//\n//\trows, err := q.ListFoos(ctx)\nfunc (q *Queries) ListFoos(",
+		"// Example:\n//\n//\terr := q.UpdateFoo(ctx, arg)\nfunc (q *Queries) UpdateFoo(",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["query.sql.go"], "Example:") {
+		t.Errorf("query.sql.go contains examples by default:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestEmitUnexported(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: UpdateFoo :exec
+UPDATE foo SET name = $2 WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitUnexported: true, EmitInterface: true})
+	for _, expected := range []string{
+		"const getFooQuery = `-- name: getFoo :one",
+		"func (q *Queries) getFoo(ctx context.Context, id int32) (Foo, error) {",
+		"type updateFooParams struct {",
+		"func (q *Queries) updateFoo(ctx context.Context, arg updateFooParams) error {",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if !strings.Contains(output["query.sql.go"], "func (q *Queries) GetFoo(") {
+		t.Errorf("query.sql.go does not export GetFoo:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestStrictArrayTypes(t *testing.T) {
+	for _, tc := range []struct {
+		schema  string
+		queries string
+		err     string
+	}{
+		{
+			`CREATE TABLE foo (id int not null, attrs hstore[] not null);`,
+			`
+-- name: ListFoos :many
+SELECT * FROM foo;
+`,
+			"Foo.Attrs: unsupported array element type",
+		},
+		{
+			`CREATE TABLE foo (id int not null);`,
+			`
+-- name: CountFoos :one
+SELECT count(*) FROM foo WHERE $1::hstore[] IS NOT NULL;
+`,
+			"CountFoos dollar_1: unsupported array element type",
+		},
+	} {
+		r, settings := parsePackage(t, tc.schema, tc.queries, PackageSettings{StrictArrayTypes: true})
+		_, err := Generate(r, settings)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("expected error %q; got %v", tc.err, err)
+		}
+	}
+
+	output := generatePackage(t, `CREATE TABLE foo (id int not null, attrs hstore[] not null);`, `
+-- name: GetAttrs :one
+SELECT attrs FROM foo WHERE id = $1;
+`, PackageSettings{})
+	if !strings.Contains(output["models.go"], "Attrs []interface{}") {
+		t.Errorf("models.go does not fall back to []interface{} without strict_array_types:\n%s", output["models.go"])
+	}
+}
+
+func TestUnknownTypes(t *testing.T) {
+	schema := `CREATE TABLE foo (id int not null, attrs hstore not null);`
+	queries := `
+-- name: GetAttrs :one
+SELECT attrs FROM foo WHERE id = $1;
+`
+	r, settings := parsePackage(t, schema, queries, PackageSettings{StrictTypes: true})
+	if _, err := Generate(r, settings); err == nil || err.Error() != "Foo.Attrs: unsupported type" {
+		t.Errorf("expected error %q; got %v", "Foo.Attrs: unsupported type", err)
+	}
+
+	output := generatePackage(t, schema, queries, PackageSettings{StrictTypes: true, FallbackGoType: "example.com/pgtype.Text"})
+	if !strings.Contains(output["models.go"], "Attrs pgtype.Text") {
+		t.Errorf("models.go does not use the fallback type:\n%s", output["models.go"])
+	}
+	if !strings.Contains(output["models.go"], `"example.com/pgtype"`) {
+		t.Errorf("models.go does not import the fallback type:\n%s", output["models.go"])
+	}
+	if !strings.Contains(output["query.sql.go"], "func (q *Queries) GetAttrs(ctx context.Context, id int32) (pgtype.Text, error) {") {
+		t.Errorf("query.sql.go does not return the fallback type:\n%s", output["query.sql.go"])
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{FallbackGoType: "string"})
+	if !strings.Contains(output["models.go"], "Attrs string") {
+		t.Errorf("models.go does not use the basic fallback type:\n%s", output["models.go"])
+	}
+}
+
+func TestEmitQueriesFile(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitQueriesFile: true})
+	for _, constant := range []string{
+		"const getFoo = `-- name: GetFoo :one\nSELECT id, name, bio FROM foo WHERE id = $1\n`",
+		"const deleteFoo = `-- name: DeleteFoo :exec\nDELETE FROM foo WHERE id = $1\n`",
+	} {
+		if !strings.Contains(output["queries_sql.go"], constant) {
+			t.Errorf("queries_sql.go does not contain %q:\n%s", constant, output["queries_sql.go"])
+		}
+	}
+	if strings.Contains(output["query.sql.go"], "const ") {
+		t.Errorf("query.sql.go contains SQL constants:\n%s", output["query.sql.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if _, ok := output["queries_sql.go"]; ok {
+		t.Errorf("queries_sql.go generated without emit_queries_file")
+	}
+	if !strings.Contains(output["query.sql.go"], "const getFoo = ") {
+		t.Errorf("query.sql.go does not contain the SQL constants:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestEmitPing(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitPing: true, EmitInterface: true})
+	for _, expected := range []string{
+		"const ping = `SELECT 1`",
+		"func (q *Queries) Ping(ctx context.Context) error {",
+		"return q.db.QueryRowContext(ctx, ping).Scan(&one)",
+		"Ping(ctx context.Context) error\n",
+	} {
+		if !strings.Contains(output["db.go"], expected) {
+			t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["db.go"], "Ping") {
+		t.Errorf("db.go contains Ping without emit_ping:\n%s", output["db.go"])
+	}
+}
+
+func TestEmitStore(t *testing.T) {
+	queries := `
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["db.go"], "ExecTx") {
+		t.Errorf("db.go contains ExecTx without emit_store:\n%s", output["db.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{EmitStore: true})
+	for _, expected := range []string{
+		"func NewStore(db *sql.DB) *Store {",
+		"func (s *Store) ExecTx(ctx context.Context, fn func(*Queries) error) error {",
+		"return tx.Commit()",
+	} {
+		if !strings.Contains(output["db.go"], expected) {
+			t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+		}
+	}
+}
+
+func TestEmitResultPointers(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: GetFooName :one
+SELECT name FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitResultPointers: true, EmitInterface: true, EmitMock: true})
+	for _, expected := range []string{
+		"func (q *Queries) GetFoo(ctx context.Context, id int32) (*Foo, error) {",
+		"return &i, nil",
+		"func (q *Queries) GetFooName(ctx context.Context, id int32) (string, error) {",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+	if expected := "GetFoo(ctx context.Context, id int32) (*Foo, error)"; !strings.Contains(output["db.go"], expected) {
+		t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if expected := "func (q *Queries) GetFoo(ctx context.Context, id int32) (Foo, error) {"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestEmitZeroOnNoRows(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitZeroOnNoRows: true, EmitErrNotFound: true})
+	if strings.Contains(output["query.sql.go"], "err = ErrNotFound") {
+		t.Errorf("query.sql.go returns ErrNotFound:\n%s", output["query.sql.go"])
+	}
+	if expected := "if err == sql.ErrNoRows {\n\t\treturn i, nil\n\t}"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["query.sql.go"], "sql.ErrNoRows") {
+		t.Errorf("query.sql.go checks for sql.ErrNoRows by default:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestEmitCRUD(t *testing.T) {
+	schema := `
+CREATE TABLE authors (id serial primary key, name text not null, bio text);
+CREATE TABLE tags (name text not null);
+`
+	r, settings := parsePackage(t, schema, `
+-- name: ListAuthors :many
+SELECT * FROM authors ORDER BY name;
+`, PackageSettings{})
+	crud, err := crudQueries(r.Catalog, settings, PackageSettings{}, map[string]struct{}{"ListAuthors": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Queries = append(r.Queries, crud...)
+	output, err := Generate(r, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"func (q *Queries) GetAuthor(ctx context.Context, id int32) (Author, error) {",
+		"func (q *Queries) CreateAuthor(ctx context.Context, arg CreateAuthorParams) (Author, error) {",
+		"INSERT INTO authors (name, bio) VALUES ($1, $2) RETURNING id, name, bio",
+		"func (q *Queries) UpdateAuthor(ctx context.Context, arg UpdateAuthorParams) (Author, error) {",
+		"UPDATE authors SET name = $2, bio = $3 WHERE id = $1 RETURNING id, name, bio",
+		"func (q *Queries) DeleteAuthor(ctx context.Context, id int32) error {",
+	} {
+		if !strings.Contains(output["crud.sql.go"], expected) {
+			t.Errorf("crud.sql.go does not contain %q:\n%s", expected, output["crud.sql.go"])
+		}
+	}
+	if strings.Contains(output["crud.sql.go"], "ListAuthors") {
+		t.Errorf("crud.sql.go replaces the ListAuthors query:\n%s", output["crud.sql.go"])
+	}
+	if strings.Contains(output["crud.sql.go"], "Tag") {
+		t.Errorf("crud.sql.go contains queries for a table without a primary key:\n%s", output["crud.sql.go"])
+	}
+
+	crud, err = crudQueries(r.Catalog, settings, PackageSettings{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, q := range crud {
+		names = append(names, q.Name)
+	}
+	expected := []string{"GetAuthor", "ListAuthors", "CreateAuthor", "UpdateAuthor", "DeleteAuthor"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("CRUD query names differ:\n%s", diff)
+	}
+
+	settings.Rename = map[string]string{"authors": "Writer"}
+	crud, err = crudQueries(r.Catalog, settings, PackageSettings{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names = nil
+	for _, q := range crud {
+		names = append(names, q.Name)
+	}
+	expected = []string{"GetWriter", "ListWriters", "CreateWriter", "UpdateWriter", "DeleteWriter"}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("renamed CRUD query names differ:\n%s", diff)
+	}
+}
+
+func TestEmitCRUDSkipsDefaults(t *testing.T) {
+	schema := `
+CREATE TABLE sessions (
+    id         uuid        primary key default gen_random_uuid(),
+    token      text        not null,
+    created_at timestamptz not null default now()
+);
+`
+	r, settings := parsePackage(t, schema, "", PackageSettings{})
+	source := crudSource(r.Catalog, settings, PackageSettings{})
+	if expected := "INSERT INTO sessions (token) VALUES ($1) RETURNING *;"; !strings.Contains(source, expected) {
+		t.Errorf("CRUD source does not contain %q:\n%s", expected, source)
+	}
+}
+
+func TestEmitExec(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitExec: true, EmitInterface: true})
+	for _, expected := range []string{
+		"func (q *Queries) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {",
+		"return q.db.ExecContext(ctx, query, args...)",
+		"Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)\n",
+	} {
+		if !strings.Contains(output["db.go"], expected) {
+			t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["db.go"], "func (q *Queries) Exec") {
+		t.Errorf("db.go contains Exec without emit_exec:\n%s", output["db.go"])
+	}
+}
+
+func TestEmitStringer(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: ListFooNames :many
+SELECT id, name FROM foo WHERE name = $1 OR bio = $2;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitStringer: true})
+	if expected := "func (v Foo) String() string {"; !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+	for _, expected := range []string{
+		"func (v ListFooNamesParams) String() string {",
+		"func (v ListFooNamesRow) String() string {",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["models.go"], "String()") {
+		t.Errorf("models.go contains String without emit_stringer:\n%s", output["models.go"])
+	}
+}
+
+func TestEmitDeepCopy(t *testing.T) {
+	schema := `CREATE TABLE foo (id int not null, tags text[] not null, data bytea, chunks bytea[] not null);`
+	queries := `
+-- name: ListFooTags :many
+SELECT id, tags FROM foo;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{})
+	if strings.Contains(output["models.go"], "DeepCopy") {
+		t.Errorf("models.go contains DeepCopy without emit_deep_copy:\n%s", output["models.go"])
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{EmitDeepCopy: true})
+	if expected := "func (v ListFooTagsRow) DeepCopy() ListFooTagsRow {"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestStringerSkipsHiddenFields(t *testing.T) {
+	gs := GoStruct{
+		Name: "User",
+		Fields: []GoField{
+			{Name: "ID", Type: "int32", Tags: map[string]string{"json:": "id"}},
+			{Name: "Password", Type: "string", Tags: map[string]string{"json:": "-"}},
+		},
+	}
+	if diff := cmp.Diff(`"User{ID:%v}"`, gs.StringerFormat()); diff != "" {
+		t.Errorf("format mismatch:\n%s", diff)
+	}
+}
+
+func TestEmitEmptySlices(t *testing.T) {
+	queries := `
+-- name: ListFoos :many
+SELECT * FROM foo;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitEmptySlices: true})
+	if expected := "items := []Foo{}"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if expected := "var items []Foo"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q without emit_empty_slices:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestBoolParameter(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (name text not null, active boolean not null);`, `
+-- name: ListFoos :many
+SELECT name FROM foo WHERE active = $1;
+`, PackageSettings{})
+
+	expected := "func (q *Queries) ListFoos(ctx context.Context, active bool) ([]string, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestNullableBool(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (name text not null, verified boolean);`, `
+-- name: ListFoosByVerified :many
+SELECT * FROM foo WHERE verified = $1;
+`, PackageSettings{})
+
+	if expected := "\tVerified sql.NullBool\n"; !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+	expected := "func (q *Queries) ListFoosByVerified(ctx context.Context, verified sql.NullBool) ([]Foo, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestStructTags(t *testing.T) {
+	schema := `CREATE TABLE foo (byte_seq bytea not null);`
+	queries := `
+-- name: ListFoos :many
+SELECT * FROM foo;
+`
+	for _, tc := range []struct {
+		pkg      PackageSettings
+		expected string
+	}{
+		{
+			PackageSettings{EmitJSONTags: true, EmitDBTags: true},
+			"ByteSeq []byte `db:\"byte_seq\" json:\"byte_seq\"`",
+		},
+		{
+			PackageSettings{EmitDBTags: true},
+			"ByteSeq []byte `db:\"byte_seq\"`",
+		},
+		{
+			PackageSettings{EmitJSONTags: true, EmitDBTags: true, JSONTagsCaseStyle: "camel"},
+			"ByteSeq []byte `db:\"byte_seq\" json:\"byteSeq\"`",
+		},
+		{
+			PackageSettings{EmitJSONTags: true, JSONTagsCaseStyle: "pascal"},
+			"ByteSeq []byte `json:\"ByteSeq\"`",
+		},
+	} {
+		tt := tc
+		t.Run(tt.expected, func(t *testing.T) {
+			output := generatePackage(t, schema, queries, tt.pkg)
+			if !strings.Contains(output["models.go"], tt.expected) {
+				t.Errorf("models.go does not contain %q:\n%s", tt.expected, output["models.go"])
+			}
+		})
+	}
+}
+
+func TestFileHeader(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`, PackageSettings{
+		Header:    "Copyright 2020 Example, Inc.\n\nDo not share.",
+		BuildTags: "integration",
+	})
+
+	// Newer versions of gofmt add a matching //go:build line
+	constraint := "// +build integration\n\n"
+	header := `// Copyright 2020 Example, Inc.
+//
+// Do not share.
+// Code generated by sqlc. DO NOT EDIT.
+`
+	for _, name := range []string{"db.go", "models.go", "query.sql.go"} {
+		pkg := strings.Index(output[name], "package db")
+		if i := strings.Index(output[name], constraint); i < 0 || i > pkg {
+			t.Errorf("%s does not start with the build constraint:\n%s", name, output[name])
+		}
+		if i := strings.Index(output[name], header); i < 0 || i > pkg {
+			t.Errorf("%s does not start with the header:\n%s", name, output[name])
+		}
+	}
+}
+
+func TestDefaultQueryTimeout(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: ListFoos :many
+SELECT * FROM foo;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{DefaultQueryTimeout: "1500ms"})
+	if expected := "const defaultQueryTimeout = 1500 * time.Millisecond"; !strings.Contains(output["db.go"], expected) {
+		t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+	}
+	wrap := "\tctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)\n\tdefer cancel()\n"
+	if n := strings.Count(output["query.sql.go"], wrap); n != 3 {
+		t.Errorf("expected 3 methods to wrap the context; found %d:\n%s", n, output["query.sql.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["query.sql.go"], "WithTimeout") {
+		t.Errorf("query.sql.go wraps the context without default_query_timeout:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestQueryParameterLimit(t *testing.T) {
+	queries := `
+-- name: UpdateFoo :exec
+UPDATE foo SET name = $1, bio = $2 WHERE id = $3 AND name <> $4;
+
+-- name: RenameFoo :exec
+UPDATE foo SET name = $1 WHERE id = $2;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{QueryParameterLimit: 3})
+	for _, expected := range []string{
+		"type UpdateFooParams struct {",
+		"func (q *Queries) UpdateFoo(ctx context.Context, arg UpdateFooParams) error {",
+		"func (q *Queries) RenameFoo(ctx context.Context, name string, id int32) error {",
+		"q.db.ExecContext(ctx, renameFoo, name, id)",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+	if strings.Contains(output["query.sql.go"], "RenameFooParams") {
+		t.Errorf("query.sql.go generates a struct below the limit:\n%s", output["query.sql.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if !strings.Contains(output["query.sql.go"], "type RenameFooParams struct {") {
+		t.Errorf("query.sql.go does not default to a struct for two parameters:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestQuotedIdentifierNames(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE "Users" ("userId" text not null, "First Name" text);`, `
+-- name: GetUser :one
+SELECT * FROM "Users" WHERE "userId" = $1;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"UserId    string",
+		"FirstName sql.NullString",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	expected := "func (q *Queries) GetUser(ctx context.Context, userId string) (User, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestSmallintArray(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (small smallint[] not null, tiny int2[]);`, `
+-- name: ListFoos :many
+SELECT * FROM foo;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"Small []int16",
+		"Tiny  []int16",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	expected := "rows.Scan(pq.Array(&i.Small), pq.Array(&i.Tiny))"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestByteaArray(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (id int not null, chunks bytea[] not null);`, `
+-- name: ListFoos :many
+SELECT * FROM foo WHERE chunks = $1;
+`, PackageSettings{})
+
+	if expected := "Chunks [][]byte"; !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+	// pq.Array wraps [][]byte in a pq.ByteaArray
+	for _, expected := range []string{
+		"func (q *Queries) ListFoos(ctx context.Context, chunks [][]byte) ([]Foo, error) {",
+		"q.db.QueryContext(ctx, listFoos, pq.Array(chunks))",
+		"rows.Scan(&i.ID, pq.Array(&i.Chunks))",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+func TestMoneyArray(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (id int not null, price money not null, history money[]);`, `
+-- name: ListFoos :many
+SELECT * FROM foo WHERE history = $1;
+`, PackageSettings{})
+
+	for _, expected := range []string{"Price   string", "History []string"} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	for _, expected := range []string{
+		"func (q *Queries) ListFoos(ctx context.Context, history []string) ([]Foo, error) {",
+		"rows.Scan(&i.ID, &i.Price, pq.Array(&i.History))",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+func TestRecursiveCTE(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE categories (id serial primary key, parent_id integer, name text not null);`, `
+-- name: ListSubcategories :many
+WITH RECURSIVE tree AS (
+	SELECT id, parent_id, name FROM categories WHERE id = $1
+	UNION ALL
+	SELECT c.id, c.parent_id, c.name FROM categories c JOIN tree ON c.parent_id = tree.id
+)
+SELECT * FROM tree;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"type ListSubcategoriesRow struct {\n\tID       int32\n\tParentID sql.NullInt32\n\tName     string\n}",
+		"func (q *Queries) ListSubcategories(ctx context.Context, id int32) ([]ListSubcategoriesRow, error) {",
+		"SELECT id, parent_id, name FROM tree",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+// testGeneratedPackage runs `go test` on the generated files together with
+// the given test file, so the behavior of generated code can be checked
+func testGeneratedPackage(t *testing.T, output map[string]string, test string) {
+	t.Helper()
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not found")
+	}
+	dir, err := ioutil.TempDir("", "sqlc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":     "module generated\n",
+		"db_test.go": test,
+	}
+	for name, contents := range output {
+		files[name] = contents
+	}
+	for name, contents := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(gobin, "test", "-vet=off", ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code failed: %s\n%s", err, out)
+	}
+}
+
+// buildGeneratedPackages writes each set of generated files to its own
+// package under testdata and compiles them all with a single go build. The
+// packages live inside this module so that imports such as lib/pq and uuid
+// resolve through its go.mod.
+func buildGeneratedPackages(t *testing.T, packages map[string]map[string]string) {
+	t.Helper()
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not found")
+	}
+	dir, err := ioutil.TempDir("testdata", "generated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for pkg, output := range packages {
+		for name, contents := range output {
+			path := filepath.Join(dir, pkg, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	cmd := exec.Command(gobin, "build", "./"+filepath.ToSlash(dir)+"/...")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code failed to build: %s\n%s", err, out)
+	}
+}
+
+// TestGeneratedPackagesBuild compiles the output of the options that change
+// the signatures or bodies of generated methods
+func TestGeneratedPackagesBuild(t *testing.T) {
+	schema := `
+CREATE TYPE mood AS ENUM ('happy', 'sad');
+
+CREATE TABLE foo (
+    id     serial primary key,
+    name   text   not null,
+    bio    text,
+    mood   mood   not null,
+    tags   text[] not null,
+    alias  text[],
+    status text   not null check (status in ('pending', 'shipped')),
+    extra  jsonb
+);
+`
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: GetFooName :one
+SELECT name FROM foo WHERE id = $1;
+
+-- name: ListFoos :many
+SELECT * FROM foo WHERE name = $1 AND bio = $2;
+
+-- name: ListFooTags :many
+SELECT tags FROM foo WHERE mood = $1;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+
+-- name: UpdateFooNames :execrows
+UPDATE foo SET name = $2 WHERE id = $1;
+
+-- name: InsertFoos :execmany
+INSERT INTO foo (name, mood, tags, status) VALUES ($1, $2, $3, $4);
+`
+	packages := map[string]map[string]string{}
+	for name, pkg := range map[string]PackageSettings{
+		"default":    {},
+		"receiver":   {ReceiverName: "queries", EmitPreparedQueries: true, EmitPing: true, EmitStore: true, DefaultQueryTimeout: "5s"},
+		"unexported": {EmitUnexported: true, EmitInterface: true, EmitPreparedQueries: true, EmitStmtAccessors: true},
+		"pointers":   {EmitResultPointers: true, EmitMock: true, EmitInterface: true},
+		"zero":       {EmitZeroOnNoRows: true, EmitSingleFile: true, EmitEnumsFile: true},
+		"notfound":   {EmitErrNotFound: true, EmitPreparedQueries: true, EmitEmptySlices: true},
+		"store":      {EmitStore: true, ConstructorName: "NewQueries", EmitExec: true, EmitPing: true},
+		"queries":    {EmitQueriesFile: true, EmitInterface: true, EmitMethodExamples: true},
+		"values":     {EmitStringer: true, EmitDeepCopy: true, EmitJSONTags: true, EmitNullTypes: true},
+		"enums":      {EmitEnumJSON: true, EmitEnumValid: true, EmitCheckConstants: true, EmitCheckValidators: true},
+		"hook":       {EmitQueryHook: true, EmitErrClassifier: true, DefaultQueryTimeout: "5s", EmitPreparedQueries: true},
+		"suffixes":   {ParamsStructSuffix: "Args", RowStructSuffix: "Result", QueryParameterLimit: 3},
+		"arrays":     {EmitNullableArrays: true, EmitDeepCopy: true},
+		"json": {Overrides: []Override{
+			{PostgresType: "jsonb", GoType: "github.com/kyleconroy/sqlc/examples/options/settings.Settings", JSON: true},
+		}},
+	} {
+		packages[name] = generatePackage(t, schema, queries, pkg)
+	}
+
+	pkg := PackageSettings{EmitCRUD: true, EmitInterface: true, EmitMock: true}
+	r, settings := parsePackage(t, schema, queries, pkg)
+	set := map[string]struct{}{}
+	for _, q := range r.Queries {
+		set[q.Name] = struct{}{}
+	}
+	crud, err := crudQueries(r.Catalog, settings, pkg, set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Queries = append(r.Queries, crud...)
+	if packages["crud"], err = Generate(r, settings); err != nil {
+		t.Fatal(err)
+	}
+
+	buildGeneratedPackages(t, packages)
+}
+
+func TestPointerOverrideImports(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: GetBio :one
+SELECT bio FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{
+		Overrides: []Override{
+			{Column: "foo.bio", GoType: "*example.com/bio.Bio"},
+		},
+	})
+	for _, name := range []string{"models.go", "query.sql.go"} {
+		if !strings.Contains(output[name], `"example.com/bio"`) {
+			t.Errorf("%s does not import example.com/bio:\n%s", name, output[name])
+		}
+	}
+	if expected := "func (q *Queries) GetBio(ctx context.Context, id int32) (*bio.Bio, error) {"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestJSONOverride(t *testing.T) {
+	schema := `CREATE TABLE foo (id serial primary key, settings jsonb not null, extra jsonb);`
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: GetSettings :one
+SELECT settings FROM foo WHERE id = $1;
+
+-- name: UpdateSettings :exec
+UPDATE foo SET settings = $2 WHERE id = $1;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{
+		Overrides: []Override{
+			{PostgresType: "jsonb", GoType: "generated/settings.Settings", JSON: true},
+		},
+	})
+	for _, expected := range []string{
+		"err := row.Scan(&i.ID, jsonValue{&i.Settings}, &i.Extra)",
+		"err := row.Scan(jsonValue{&settings})",
+		"q.db.ExecContext(ctx, updateSettings, arg.ID, jsonValue{arg.Settings})",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+	if expected := "\tExtra    json.RawMessage\n"; !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+}
+
+func TestByteaScannerOverride(t *testing.T) {
+	schema := `CREATE TABLE foo (id serial primary key, data bytea not null, thumb bytea);`
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: GetData :one
+SELECT data FROM foo WHERE id = $1;
+
+-- name: UpdateData :exec
+UPDATE foo SET data = $2 WHERE id = $1;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{
+		Overrides: []Override{
+			{PostgresType: "bytea", GoType: "generated/blob.Blob"},
+		},
+	})
+	for name, expected := range map[string][]string{
+		"models.go":    {"import (\n\t\"generated/blob\"\n)", "Data  blob.Blob\n", "Thumb []byte\n"},
+		"query.sql.go": {"import (\n\t\"context\"\n\n\t\"generated/blob\"\n)", "err := row.Scan(&data)", "UpdateData(ctx context.Context, arg UpdateDataParams) error"},
+	} {
+		for _, e := range expected {
+			if !strings.Contains(output[name], e) {
+				t.Errorf("%s does not contain %q:\n%s", name, e, output[name])
+			}
+		}
+	}
+}
+
+func TestEmitErrClassifier(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitErrClassifier: true})
+	if expected := "func ClassifyError(err error) error {"; !strings.Contains(output["db.go"], expected) {
+		t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["db.go"], "ClassifyError") {
+		t.Errorf("db.go contains ClassifyError without emit_err_classifier:\n%s", output["db.go"])
+	}
+}
+
+func TestCompositeScanValue(t *testing.T) {
+	output := generatePackage(t, `
+CREATE TYPE pair AS (id int, label text);
+CREATE TABLE foo (p pair not null);
+`, `
+-- name: ListFoos :many
+SELECT p FROM foo;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"func (c *Pair) Scan(src interface{}) error {",
+		"if err := scanCompositeField(&c.Label, fields[1]); err != nil {",
+		"func (c Pair) Value() (driver.Value, error) {\n\treturn formatComposite(c.ID, c.Label)\n}",
+		"func parseComposite(src interface{}) ([]*string, error) {",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+}
+
+func TestTableTypeOverride(t *testing.T) {
+	overrides := []Override{
+		{
+			GoType:       "example.com/events.Time",
+			PostgresType: "timestamptz",
+			Table:        "events",
+		},
+		{
+			GoType:       "example.com/other.Time",
+			PostgresType: "timestamptz",
+		},
+	}
+	for i := range overrides {
+		if err := overrides[i].Parse(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgName := "test_table_type"
+
+	r := Result{packageName: pkgName}
+	mockSettings.PackageMap[pkgName] = PackageSettings{
+		Overrides: overrides,
+	}
+
+	for _, tc := range []struct {
+		col    pg.Column
+		goType string
+	}{
+		{pg.Column{DataType: "timestamptz", NotNull: true, Table: pg.FQN{Schema: "public", Rel: "events"}}, "events.Time"},
+		{pg.Column{DataType: "timestamptz", NotNull: true, Table: pg.FQN{Schema: "public", Rel: "users"}}, "other.Time"},
+		{pg.Column{DataType: "timestamptz", NotNull: true, Table: pg.FQN{Schema: "archive", Rel: "events"}}, "other.Time"},
+		{pg.Column{DataType: "text", NotNull: true, Table: pg.FQN{Schema: "public", Rel: "events"}}, "string"},
+	} {
+		col := tc.col
+		goType := tc.goType
+		t.Run(col.Table.String()+"-"+goType, func(t *testing.T) {
+			if actual := r.goType(col, mockSettings); actual != goType {
+				t.Errorf("expected Go type for %s to be %s, not %s", col.DataType, goType, actual)
+			}
+		})
+	}
+}
+
+func TestPrimaryKeyOverride(t *testing.T) {
+	schema := `
+CREATE TABLE users (id bigserial primary key, name text not null, manager_id bigint);
+CREATE TABLE memberships (user_id bigint, org_id bigint, role text not null, PRIMARY KEY (user_id, org_id));
+`
+	queries := `
+-- name: GetUser :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: ListMemberships :many
+SELECT * FROM memberships;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{
+		Overrides: []Override{
+			{PrimaryKey: true, GoType: "example.com/ids.ID"},
+		},
+	})
+	for _, expected := range []string{
+		"\tID        ids.ID\n",
+		"\tManagerID sql.NullInt64\n",
+		"\tUserID ids.ID\n",
+		"\tOrgID  ids.ID\n",
+		"\tRole   string\n",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	if expected := "func (q *Queries) GetUser(ctx context.Context, id ids.ID) (User, error) {"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestSerialPrimaryKeyOverride(t *testing.T) {
+	schema := `
+CREATE TABLE users (id bigserial primary key, name text not null);
+CREATE TABLE accounts (id bigint primary key, name text not null);
+`
+	queries := `
+-- name: CreateUser :one
+INSERT INTO users (name) VALUES ($1) RETURNING *;
+
+-- name: CreateAccount :one
+INSERT INTO accounts (id, name) VALUES ($1, $2) RETURNING *;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{
+		Overrides: []Override{
+			{PrimaryKey: true, PostgresType: "bigserial", GoType: "example.com/ids.ID"},
+		},
+	})
+	for _, expected := range []string{
+		"type User struct {\n\tID   ids.ID\n",
+		"type Account struct {\n\tID   int64\n",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	if expected := "func (q *Queries) CreateUser(ctx context.Context, name string) (User, error) {"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestTimestampTypeOverride(t *testing.T) {
+	o := Override{
+		GoType:       "example.com/utc.Time",
+		PostgresType: "pg_catalog.timestamp",
+	}
+	if err := o.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgName := "test_timestamp_type"
+
+	r := Result{packageName: pkgName}
+	mockSettings.PackageMap[pkgName] = PackageSettings{
+		Overrides: []Override{o},
+	}
+
+	for _, tc := range []struct {
+		col    pg.Column
+		goType string
+	}{
+		{pg.Column{DataType: "pg_catalog.timestamp", NotNull: true}, "utc.Time"},
+		{pg.Column{DataType: "pg_catalog.timestamptz", NotNull: true}, "time.Time"},
+		{pg.Column{DataType: "timestamptz", NotNull: true}, "time.Time"},
+		{pg.Column{DataType: "pg_catalog.timestamp"}, "sql.NullTime"},
+	} {
+		col := tc.col
+		goType := tc.goType
+		t.Run(col.DataType+"-"+goType, func(t *testing.T) {
+			if actual := r.goType(col, mockSettings); actual != goType {
+				t.Errorf("expected Go type for %s to be %s, not %s", col.DataType, goType, actual)
+			}
+		})
+	}
+}
+
+func TestTimestamptzTypeOverride(t *testing.T) {
+	schema := `CREATE TABLE events (id int not null, created_at timestamptz not null, updated_at timestamp with time zone, starts_at timestamp not null);`
+	queries := `
+-- name: ListEvents :many
+SELECT * FROM events;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{
+		Overrides: []Override{
+			{PostgresType: "timestamptz", GoType: "example.com/utc.Time"},
+			{PostgresType: "timestamptz", GoType: "example.com/utc.NullTime", Null: true},
+		},
+	})
+	for _, expected := range []string{
+		"CreatedAt utc.Time\n",
+		"UpdatedAt utc.NullTime\n",
+		"StartsAt  time.Time\n",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+}
+
+func TestTimeOfDayOverride(t *testing.T) {
+	schema := `CREATE TABLE shifts (starts time not null, ends timetz not null, day date not null);`
+	queries := `
+-- name: ListShifts :many
+SELECT * FROM shifts;
+`
+	output := generatePackage(t, schema, queries, PackageSettings{
+		Overrides: []Override{
+			{PostgresType: "pg_catalog.time", GoType: "example.com/clock.TimeOfDay"},
+		},
+	})
+	for _, expected := range []string{
+		"\tStarts clock.TimeOfDay\n",
+		"\tEnds   time.Time\n",
+		"\tDay    time.Time\n",
+		"\"example.com/clock\"",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+
+	output = generatePackage(t, schema, queries, PackageSettings{})
+	if expected := "\tStarts time.Time\n"; !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+}
+
+func TestEmitErrNotFound(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitErrNotFound: true})
+	for name, expected := range map[string]string{
+		"db.go":        `var ErrNotFound = fmt.Errorf("not found: %w", sql.ErrNoRows)`,
+		"query.sql.go": "err = ErrNotFound",
+	} {
+		if !strings.Contains(output[name], expected) {
+			t.Errorf("%s does not contain %q:\n%s", name, expected, output[name])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["db.go"], "ErrNotFound") {
+		t.Errorf("db.go contains ErrNotFound without emit_err_not_found:\n%s", output["db.go"])
+	}
+}
+
+func TestUUIDArray(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (name text not null, related uuid[] not null);`, `
+-- name: ListRelated :one
+SELECT related FROM foo WHERE name = $1;
+
+-- name: ListFoos :many
+SELECT * FROM foo WHERE related && $1::uuid[];
+`, PackageSettings{})
+
+	if expected := "Related []uuid.UUID"; !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+	for _, expected := range []string{
+		"\"github.com/google/uuid\"",
+		"\"github.com/lib/pq\"",
+		"func (q *Queries) ListRelated(ctx context.Context, name string) ([]uuid.UUID, error) {",
+		"row.Scan(pq.Array(&related))",
+		"func (q *Queries) ListFoos(ctx context.Context, dollar_1 []uuid.UUID) ([]Foo, error) {",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+func TestEmitNullTypes(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (id serial primary key, count integer, bio text);`, `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+`, PackageSettings{EmitNullTypes: true, EmitJSONTags: true})
+
+	for _, expected := range []string{
+		"Count NullInt32",
+		"Bio   NullString",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+}
+
+func TestLikeParameter(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (id integer not null, name text not null);`, `
+-- name: SearchFoos :many
+SELECT name FROM foo WHERE id::text LIKE $1;
+`, PackageSettings{})
+
+	expected := "func (q *Queries) SearchFoos(ctx context.Context, id string) ([]string, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	queries := `
+-- name: ListPeople :many
+SELECT * FROM person;
+`
+	output := generatePackage(t, moodSchema, queries, PackageSettings{})
+	if strings.Contains(output["models.go"], "MarshalJSON") {
+		t.Errorf("models.go contains JSON methods without emit_enum_json:\n%s", output["models.go"])
+	}
+
+	output = generatePackage(t, moodSchema, queries, PackageSettings{EmitEnumJSON: true})
+	for _, expected := range []string{
+		"func (e Mood) MarshalJSON() ([]byte, error) {",
+		"func (e *Mood) UnmarshalJSON(b []byte) error {",
+		`return fmt.Errorf("invalid Mood value %q", s)`,
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+}
+
+func TestGeneratedCodeIsFormatted(t *testing.T) {
+	schema := moodSchema + `
+CREATE TYPE pair AS (id int, label text);
+CREATE TABLE foo (id serial primary key, name text, tags text[] not null, p pair, created_at timestamptz not null);
+`
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: ListPeople :many
+SELECT * FROM person WHERE mood = $1;
+
+-- name: UpdateFoo :execrows
+UPDATE foo SET name = $2, tags = $3 WHERE id = $1;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+`
+	for _, pkg := range []PackageSettings{
+		{},
+		{EmitJSONTags: true, EmitDBTags: true, EmitInterface: true, EmitPreparedQueries: true},
+		{EmitEnumsFile: true, EmitPing: true, EmitErrNotFound: true, EmitNullTypes: true, DefaultQueryTimeout: "5s"},
+		{Header: "Copyright", BuildTags: "integration", EmitMock: true, EmitInterface: true},
+	} {
+		first := generatePackage(t, schema, queries, pkg)
+		second := generatePackage(t, schema, queries, pkg)
+		if diff := cmp.Diff(first, second); diff != "" {
+			t.Errorf("%+v: output is not deterministic:\n%s", pkg, diff)
+		}
+		for name, code := range first {
+			formatted, err := format.Source([]byte(code))
+			if err != nil {
+				t.Fatalf("%s: %s", name, err)
+			}
+			if diff := cmp.Diff(string(formatted), code); diff != "" {
+				t.Errorf("%+v: %s is not gofmt clean:\n%s", pkg, name, diff)
+			}
+		}
+	}
+}
+
+func TestRegenerationIsByteIdentical(t *testing.T) {
+	schema := `
+CREATE SCHEMA audit;
+CREATE TYPE status AS ENUM ('open', 'closed');
+CREATE TYPE audit.status AS ENUM ('pending', 'done');
+CREATE TABLE tickets (zeta text not null, alpha status not null, mid int);
+CREATE TABLE audit.tickets (id int not null, state audit.status not null);
+CREATE TABLE users (id int not null, name text);
+`
+	queries := `
+-- name: ListTickets :many
+SELECT * FROM tickets;
+
+-- name: ListUsers :many
+SELECT * FROM users WHERE name = $1;
+`
+	first := generatePackage(t, schema, queries, PackageSettings{})
+	for i := 0; i < 20; i++ {
+		if diff := cmp.Diff(first, generatePackage(t, schema, queries, PackageSettings{})); diff != "" {
+			t.Fatalf("regeneration %d differs:\n%s", i, diff)
+		}
+	}
+
+	for _, expected := range []string{
+		"type Ticket struct {\n\tZeta  string\n\tAlpha Status\n\tMid   sql.NullInt32\n}",
+		"type AuditTicket struct {\n\tID    int32\n\tState AuditStatus\n}",
+	} {
+		if !strings.Contains(first["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, first["models.go"])
+		}
+	}
+}
+
+func TestReturningAlias(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: CreateFoo :one
+INSERT INTO foo (name) VALUES ($1) RETURNING id AS new_id, name AS new_name;
+
+-- name: RenameFoo :one
+UPDATE foo SET name = $2 WHERE id = $1 RETURNING name AS old_name;
+`, PackageSettings{EmitJSONTags: true})
+
+	for _, expected := range []string{
+		"NewID   int32  `json:\"new_id\"`",
+		"NewName string `json:\"new_name\"`",
+		"err := row.Scan(&i.NewID, &i.NewName)",
+		"var old_name string",
+	} {
+		if !strings.Contains(output["query.sql.go"], expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+		}
+	}
+}
+
+func TestColumnComments(t *testing.T) {
+	output := generatePackage(t, fooSchema+`
+COMMENT ON COLUMN foo.bio IS 'Short biography';
+`, `
+-- name: GetFoo :one
+SELECT bio, name FROM foo WHERE id = $1;
+
+-- name: UpdateFoo :exec
+UPDATE foo SET name = $2 WHERE id = $1 AND bio = $3;
+`, PackageSettings{})
+
+	expected := "\t// Short biography\n\tBio sql.NullString\n"
+	if !strings.Contains(output["models.go"], expected) {
+		t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+	}
+	// Both GetFooRow and UpdateFooParams include the column
+	if n := strings.Count(output["query.sql.go"], "\t// Short biography\n\tBio "); n != 2 {
+		t.Errorf("expected the comment on 2 query structs; found %d:\n%s", n, output["query.sql.go"])
+	}
+}
+
+func TestFixedWidthCharacterTypes(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (code char(3) not null, region character(2), kind bpchar not null);`, `
+-- name: ListFoos :many
+SELECT * FROM foo;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"Code   string",
+		"Region sql.NullString",
+		"Kind   string",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+}
+
+func TestVaryingCharacterLength(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (email varchar(255) not null, code character varying(10) not null, nick varchar(32), note varchar);`, `
+-- name: ListFoos :many
+SELECT * FROM foo WHERE code = $1::character varying(10);
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"Email string",
+		"Code  string",
+		"Nick  sql.NullString",
+		"Note  sql.NullString",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	if !strings.Contains(output["query.sql.go"], "(ctx context.Context, dollar_1 string)") {
+		t.Errorf("query.sql.go does not take a string parameter:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestIntervalAsString(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (ttl interval not null, grace interval);`, `
+-- name: ListFoos :many
+SELECT * FROM foo WHERE ttl > $1::interval;
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"Ttl   string",
+		"Grace sql.NullString",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	if !strings.Contains(output["query.sql.go"], "(ctx context.Context, dollar_1 string)") {
+		t.Errorf("query.sql.go does not take a string parameter:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestNumericPrecision(t *testing.T) {
+	output := generatePackage(t, `CREATE TABLE foo (price numeric not null, amount numeric(10,2) not null, tax numeric(10,2), rate decimal(5));`, `
+-- name: ListFoos :many
+SELECT * FROM foo WHERE amount > $1::numeric(10,2);
+`, PackageSettings{})
+
+	for _, expected := range []string{
+		"Price  string",
+		"Amount string",
+		"Tax    sql.NullString",
+		"Rate   sql.NullString",
+	} {
+		if !strings.Contains(output["models.go"], expected) {
+			t.Errorf("models.go does not contain %q:\n%s", expected, output["models.go"])
+		}
+	}
+	if !strings.Contains(output["query.sql.go"], "(ctx context.Context, dollar_1 string)") {
+		t.Errorf("query.sql.go does not take a string parameter:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestEmitMock(t *testing.T) {
+	queries := `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: ListFoos :many
+SELECT * FROM foo;
+
+-- name: UpdateFoo :execrows
+UPDATE foo SET name = $2 WHERE id = $1;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitMock: true, EmitInterface: true, EmitPing: true, EmitExec: true})
+	for _, expected := range []string{
+		"func (m *MockQuerier) GetFoo(ctx context.Context, id int32) (Foo, error) {",
+		"m.GetFooCalls = append(m.GetFooCalls, []interface{}{id})",
+		"m.ExecCalls = append(m.ExecCalls, append([]interface{}{query}, args...))",
+		"func (m *MockQuerier) Ping(ctx context.Context) error {",
+		"var _ Querier = (*MockQuerier)(nil)",
+	} {
+		if !strings.Contains(output["mock.go"], expected) {
+			t.Errorf("mock.go does not contain %q:\n%s", expected, output["mock.go"])
+		}
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if _, ok := output["mock.go"]; ok {
+		t.Errorf("mock.go generated without emit_mock:\n%s", output["mock.go"])
+	}
+}
+
+func TestSkipQuery(t *testing.T) {
+	output := generatePackage(t, fooSchema, `
+-- name: GetFoo :one
+SELECT * FROM foo WHERE id = $1;
+
+-- name: ListFoosByColor :skip
+SELECT * FROM foo WHERE color = $1;
+`, PackageSettings{EmitInterface: true})
+
+	for name, code := range output {
+		if strings.Contains(code, "ListFoosByColor") {
+			t.Errorf("%s contains skipped query ListFoosByColor:\n%s", name, code)
+		}
+	}
+	if !strings.Contains(output["query.sql.go"], "func (q *Queries) GetFoo(") {
+		t.Errorf("query.sql.go is missing GetFoo:\n%s", output["query.sql.go"])
+	}
+}
+
+func TestExecMany(t *testing.T) {
+	queries := `
+-- name: UpsertFoos :execmany
+INSERT INTO foo (id, name, bio) VALUES ($1, $2, lower($3))
+ON CONFLICT (id) DO UPDATE SET name = excluded.name, bio = excluded.bio;
+
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitInterface: true, EmitPreparedQueries: true})
+
+	if !strings.Contains(output["query.sql.go"], "func (q *Queries) UpsertFoos(ctx context.Context, arg []UpsertFoosParams) error {") {
+		t.Errorf("query.sql.go does not contain UpsertFoos taking a slice:\n%s", output["query.sql.go"])
+	}
+	if !strings.Contains(output["db.go"], "deleteFooStmt") || strings.Contains(output["db.go"], "upsertFoosStmt") {
+		t.Errorf("db.go should only prepare DeleteFoo:\n%s", output["db.go"])
+	}
+
+	testGeneratedPackage(t, output, `package db
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// recordingDB records the statements passed to ExecContext
+type recordingDB struct {
+	DBTX
+	queries []string
+	args    [][]interface{}
+}
+
+func (db *recordingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.queries = append(db.queries, query)
+	db.args = append(db.args, args)
+	return nil, nil
+}
+
+func TestUpsertFoos(t *testing.T) {
+	db := &recordingDB{}
+	err := New(db).UpsertFoos(context.Background(), []UpsertFoosParams{
+		{ID: 1, Name: "a", Lower: "X"},
+		{ID: 2, Name: "b", Lower: "Y"},
+		{ID: 3, Name: "c", Lower: "Z"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "INSERT INTO foo (id, name, bio) VALUES ($1, $2, lower($3)), ($4, $5, lower($6)), ($7, $8, lower($9))\nON CONFLICT (id) DO UPDATE SET name = excluded.name, bio = excluded.bio"
+	if len(db.queries) != 1 || db.queries[0] != expected {
+		t.Errorf("query mismatch:\n%q\nexpected:\n%s", db.queries, expected)
+	}
+	args := []interface{}{
+		int32(1), "a", "X",
+		int32(2), "b", "Y",
+		int32(3), "c", "Z",
+	}
+	if len(db.args) != 1 || !reflect.DeepEqual(db.args[0], args) {
+		t.Errorf("args mismatch: %v", db.args)
+	}
+
+	db = &recordingDB{}
+	if err := New(db).UpsertFoos(context.Background(), nil); err != nil || len(db.queries) != 0 {
+		t.Errorf("empty upsert ran %q: %v", db.queries, err)
+	}
+}
+
+func TestUpsertFoosBatches(t *testing.T) {
+	db := &recordingDB{}
+	// One more row than fits in 65535 parameters
+	err := New(db).UpsertFoos(context.Background(), make([]UpsertFoosParams, 65535/3+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(db.queries) != 2 {
+		t.Fatalf("expected 2 statements; got %d", len(db.queries))
+	}
+	if len(db.args[0]) != 65535 || len(db.args[1]) != 3 {
+		t.Errorf("expected 65535 and 3 arguments; got %d and %d", len(db.args[0]), len(db.args[1]))
+	}
+	if !strings.HasPrefix(db.queries[1], "INSERT INTO foo (id, name, bio) VALUES ($1, $2, lower($3))\n") {
+		t.Errorf("second statement doesn't restart numbering:\n%s", db.queries[1])
+	}
+}
+`)
+}
+
+func TestEmitQueryHook(t *testing.T) {
+	queries := `
+-- name: DeleteFoo :exec
+DELETE FROM foo WHERE id = $1;
+`
+	output := generatePackage(t, fooSchema, queries, PackageSettings{EmitQueryHook: true})
+	if expected := "var QueryHook func(ctx context.Context, name, query string)"; !strings.Contains(output["db.go"], expected) {
+		t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+	}
+	if expected := "if QueryHook != nil {\n\t\tQueryHook(ctx, \"DeleteFoo\", deleteFoo)\n\t}"; !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+
+	output = generatePackage(t, fooSchema, queries, PackageSettings{})
+	if strings.Contains(output["db.go"], "QueryHook") || strings.Contains(output["query.sql.go"], "QueryHook") {
+		t.Errorf("generated a query hook without emit_query_hook")
+	}
+}
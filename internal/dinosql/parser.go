@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,6 +22,26 @@ import (
 	nodes "github.com/lfittl/pg_query_go/nodes"
 )
 
+var callPattern = regexp.MustCompile(`(?im)^[ \t]*(call)\b`)
+
+// explainParseError points syntax errors on CALL statements, which the
+// PostgreSQL 10 parser predates, at the statement with a clearer message.
+// TODO: Generate :exec methods for CALL once the parser supports it
+func explainParseError(source string, err error) error {
+	if !strings.EqualFold(err.Error(), `syntax error at or near "CALL"`) {
+		return err
+	}
+	loc := 0
+	if m := callPattern.FindStringSubmatchIndex(source); m != nil {
+		loc = m[2]
+	}
+	return core.Error{
+		Code:     "42601",
+		Message:  err.Error() + ": CALL statements are not supported yet, use SELECT with a function instead",
+		Location: loc,
+	}
+}
+
 func keepSpew() {
 	spew.Dump("hello world")
 }
@@ -58,26 +79,40 @@ func (e *ParserErr) Error() string {
 	return fmt.Sprintf("multiple errors: %d errors", len(e.Errs))
 }
 
-func ReadSQLFiles(path string) ([]string, error) {
-	f, err := os.Stat(path)
-	if err != nil {
-		return nil, fmt.Errorf("path %s does not exist", path)
-	}
-
+// ReadSQLFiles returns the .sql files found at each path. A path may be a
+// file, a directory, or a glob pattern matching files and directories. A file
+// matched by more than one path is only returned the first time.
+func ReadSQLFiles(paths ...string) ([]string, error) {
 	var files []string
-	if f.IsDir() {
-		listing, err := ioutil.ReadDir(path)
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(pattern)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("path %s is not a valid pattern: %w", pattern, err)
 		}
-		for _, f := range listing {
-			files = append(files, filepath.Join(path, f.Name()))
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("path %s does not exist", pattern)
+		}
+		for _, path := range matches {
+			f, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("path %s does not exist", path)
+			}
+			if f.IsDir() {
+				listing, err := ioutil.ReadDir(path)
+				if err != nil {
+					return nil, err
+				}
+				for _, f := range listing {
+					files = append(files, filepath.Join(path, f.Name()))
+				}
+			} else {
+				files = append(files, path)
+			}
 		}
-	} else {
-		files = append(files, path)
 	}
 
 	var sql []string
+	seen := map[string]bool{}
 	for _, filename := range files {
 		if !strings.HasSuffix(filename, ".sql") {
 			continue
@@ -85,6 +120,11 @@ func ReadSQLFiles(path string) ([]string, error) {
 		if strings.HasPrefix(filepath.Base(filename), ".") {
 			continue
 		}
+		clean := filepath.Clean(filename)
+		if seen[clean] {
+			continue
+		}
+		seen[clean] = true
 		sql = append(sql, filename)
 	}
 	return sql, nil
@@ -115,6 +155,10 @@ func ParseCatalog(schema string) (core.Catalog, error) {
 				merr.Add(filename, contents, location(stmt), err)
 				continue
 			}
+			if err := createView(&c, stmt); err != nil {
+				merr.Add(filename, contents, location(stmt), err)
+				continue
+			}
 			if err := catalog.Update(&c, stmt); err != nil {
 				merr.Add(filename, contents, location(stmt), err)
 				continue
@@ -137,6 +181,9 @@ func updateCatalog(c *core.Catalog, tree pg.ParsetreeList) error {
 		if err := validateFuncCall(c, stmt); err != nil {
 			return err
 		}
+		if err := createView(c, stmt); err != nil {
+			return err
+		}
 		if err := catalog.Update(c, stmt); err != nil {
 			return err
 		}
@@ -144,6 +191,72 @@ func updateCatalog(c *core.Catalog, tree pg.ParsetreeList) error {
 	return nil
 }
 
+// createView adds the view created by a CREATE VIEW or CREATE MATERIALIZED
+// VIEW statement to the catalog as a table, with the output columns of its
+// query, so that queries can select from it. Other statements are left to
+// catalog.Update.
+func createView(c *core.Catalog, stmt nodes.Node) error {
+	raw, ok := stmt.(nodes.RawStmt)
+	if !ok {
+		return nil
+	}
+	var kind string
+	var rel *nodes.RangeVar
+	var aliases nodes.List
+	var query nodes.Node
+	var replace, ifNotExists bool
+	switch n := raw.Stmt.(type) {
+	case nodes.ViewStmt:
+		kind, rel, aliases, query, replace = "CREATE VIEW", n.View, n.Aliases, n.Query, n.Replace
+	case nodes.CreateTableAsStmt:
+		if n.Relkind != nodes.OBJECT_MATVIEW || n.Into == nil {
+			return nil
+		}
+		kind, rel, aliases, query, ifNotExists = "CREATE MATERIALIZED VIEW", n.Into.Rel, n.Into.ColNames, n.Query, n.IfNotExists
+	default:
+		return nil
+	}
+	fqn, err := catalog.ParseRange(rel)
+	if err != nil {
+		return err
+	}
+	schema, exists := c.Schemas[fqn.Schema]
+	if !exists {
+		return core.ErrorSchemaDoesNotExist(fqn.Schema)
+	}
+	if _, exists := schema.Tables[fqn.Rel]; exists && !replace {
+		if ifNotExists {
+			return nil
+		}
+		return core.ErrorRelationAlreadyExists(fqn.Rel)
+	}
+	cols, err := outputColumns(*c, query)
+	if err != nil {
+		return err
+	}
+	names := stringSlice(aliases)
+	if len(names) > len(cols) {
+		return fmt.Errorf("%s specifies more column names than columns", kind)
+	}
+	table := core.Table{ID: fqn, Name: fqn.Rel}
+	for i, col := range cols {
+		name := col.Name
+		if i < len(names) {
+			name = names[i]
+		}
+		table.Columns = append(table.Columns, core.Column{
+			Name:     name,
+			DataType: col.DataType,
+			NotNull:  col.NotNull,
+			IsArray:  col.IsArray,
+			Comment:  col.Comment,
+			Table:    fqn,
+		})
+	}
+	schema.Tables[fqn.Rel] = table
+	return nil
+}
+
 func join(list nodes.List, sep string) string {
 	items := []string{}
 	for _, item := range list.Items {
@@ -176,9 +289,12 @@ type Query struct {
 	Columns  []core.Column
 	Params   []Parameter
 	Name     string
-	Cmd      string // TODO: Pick a better name. One of: one, many, exec, execrows
+	Cmd      string // TODO: Pick a better name. One of: one, many, exec, execrows, execmany
 	Comments []string
 
+	// Tables and views the query reads from or writes to
+	Tables []core.FQN
+
 	// XXX: Hack
 	Filename string
 }
@@ -196,35 +312,18 @@ func (r Result) PkgName() string {
 	return r.packageName
 }
 
-func ParseQueries(c core.Catalog, pkg PackageSettings) (*Result, error) {
-	f, err := os.Stat(pkg.Queries)
+func ParseQueries(c core.Catalog, settings GenerateSettings, pkg PackageSettings) (*Result, error) {
+	files, err := ReadSQLFiles(pkg.Queries...)
 	if err != nil {
-		return nil, fmt.Errorf("path %s does not exist", pkg.Queries)
+		return nil, err
 	}
 
-	var files []string
-	if f.IsDir() {
-		listing, err := ioutil.ReadDir(pkg.Queries)
-		if err != nil {
-			return nil, err
-		}
-		for _, f := range listing {
-			files = append(files, filepath.Join(pkg.Queries, f.Name()))
-		}
-	} else {
-		files = append(files, pkg.Queries)
-	}
+	c.SearchPath = pkg.SearchPath
 
 	merr := NewParserErr()
 	var q []*Query
 	set := map[string]struct{}{}
 	for _, filename := range files {
-		if !strings.HasSuffix(filename, ".sql") {
-			continue
-		}
-		if strings.HasPrefix(filepath.Base(filename), ".") {
-			continue
-		}
 		blob, err := ioutil.ReadFile(filename)
 		if err != nil {
 			merr.Add(filename, "", 0, err)
@@ -233,12 +332,12 @@ func ParseQueries(c core.Catalog, pkg PackageSettings) (*Result, error) {
 		source := string(blob)
 		tree, err := pg.Parse(source)
 		if err != nil {
-			merr.Add(filename, source, 0, err)
+			merr.Add(filename, source, 0, explainParseError(source, err))
 			continue
 		}
 		for _, stmt := range tree.Statements {
 			query, err := parseQuery(c, stmt, source)
-			if err == errUnsupportedStatementType {
+			if err == errUnsupportedStatementType || err == errSkippedQuery {
 				continue
 			}
 			if err != nil {
@@ -261,8 +360,15 @@ func ParseQueries(c core.Catalog, pkg PackageSettings) (*Result, error) {
 	if len(merr.Errs) > 0 {
 		return nil, merr
 	}
+	if pkg.EmitCRUD {
+		crud, err := crudQueries(c, settings, pkg, set)
+		if err != nil {
+			return nil, err
+		}
+		q = append(q, crud...)
+	}
 	if len(q) == 0 {
-		return nil, fmt.Errorf("path %s contains no queries", pkg.Queries)
+		return nil, fmt.Errorf("path %s contains no queries", strings.Join(pkg.Queries, ", "))
 	}
 	return &Result{
 		Catalog:     c,
@@ -370,7 +476,7 @@ func ParseMetadata(t string, commentStyle CommentSyntax) (string, string, error)
 			part = part[:len(part)-1] // removes the trailing "*/" element
 		}
 		if len(part) == 2 {
-			return "", "", fmt.Errorf("missing query type [':one', ':many', ':exec', ':execrows']: %s", line)
+			return "", "", fmt.Errorf("missing query type [':one', ':many', ':exec', ':execrows', ':execmany', ':skip']: %s", line)
 		}
 		if len(part) != 4 {
 			return "", "", fmt.Errorf("invalid query comment: %s", line)
@@ -378,7 +484,7 @@ func ParseMetadata(t string, commentStyle CommentSyntax) (string, string, error)
 		queryName := part[2]
 		queryType := strings.TrimSpace(part[3])
 		switch queryType {
-		case ":one", ":many", ":exec", ":execrows":
+		case ":one", ":many", ":exec", ":execrows", ":execmany", ":skip":
 		default:
 			return "", "", fmt.Errorf("invalid query type: %s", queryType)
 		}
@@ -392,6 +498,19 @@ func ParseMetadata(t string, commentStyle CommentSyntax) (string, string, error)
 
 func validateCmd(n nodes.Node, name, cmd string) error {
 	// TODO: Convert cmd to an enum
+	if cmd == ":execmany" {
+		stmt, ok := n.(nodes.InsertStmt)
+		if !ok {
+			return fmt.Errorf("query %q specifies parameter %q without being an INSERT statement", name, cmd)
+		}
+		if sel, ok := stmt.SelectStmt.(nodes.SelectStmt); !ok || len(sel.ValuesLists) != 1 {
+			return fmt.Errorf("query %q specifies parameter %q without containing a single VALUES row", name, cmd)
+		}
+		if len(stmt.ReturningList.Items) > 0 {
+			return fmt.Errorf("query %q specifies parameter %q but contains a RETURNING clause", name, cmd)
+		}
+		return nil
+	}
 	if !(cmd == ":many" || cmd == ":one") {
 		return nil
 	}
@@ -414,23 +533,78 @@ func validateCmd(n nodes.Node, name, cmd string) error {
 	return nil
 }
 
+var paramPattern = regexp.MustCompile(`\$([0-9]+)`)
+
+// valuesRow returns the offsets of the parentheses around the row following
+// the first VALUES keyword in sql
+func valuesRow(sql string) (int, int, bool) {
+	isIdent := func(i int) bool {
+		if i < 0 || i >= len(sql) {
+			return false
+		}
+		c := sql[i]
+		return c == '_' || c == '$' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+	}
+	start, depth := -1, 0
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(sql[i+1:], c)
+			if end == -1 {
+				return 0, 0, false
+			}
+			i += end + 1
+		case start == -1:
+			if i+6 > len(sql) || !strings.EqualFold(sql[i:i+6], "values") || isIdent(i-1) || isIdent(i+6) {
+				continue
+			}
+			rest := strings.TrimLeft(sql[i+6:], " \t\r\n")
+			if !strings.HasPrefix(rest, "(") {
+				return 0, 0, false
+			}
+			start = len(sql) - len(rest)
+			depth = 1
+			i = start
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return start, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// validateExecMany checks that the VALUES row of an :execmany query can be
+// repeated, which needs every parameter to appear in it
+func validateExecMany(name, sql string, params int) error {
+	start, end, ok := valuesRow(sql)
+	if !ok {
+		return fmt.Errorf("query %q specifies parameter \":execmany\" without containing a single VALUES row", name)
+	}
+	if params == 0 {
+		return fmt.Errorf("query %q specifies parameter \":execmany\" without any parameters", name)
+	}
+	if paramPattern.MatchString(sql[:start]) || paramPattern.MatchString(sql[end:]) {
+		return fmt.Errorf("query %q specifies parameter \":execmany\" with parameters outside of its VALUES row", name)
+	}
+	return nil
+}
+
 var errUnsupportedStatementType = errors.New("parseQuery: unsupported statement type")
+var errSkippedQuery = errors.New("parseQuery: query marked :skip")
 
 func parseQuery(c core.Catalog, stmt nodes.Node, source string) (*Query, error) {
-	if err := validateParamRef(stmt); err != nil {
-		return nil, err
-	}
 	raw, ok := stmt.(nodes.RawStmt)
 	if !ok {
 		return nil, errors.New("node is not a statement")
 	}
-	switch n := raw.Stmt.(type) {
+	switch raw.Stmt.(type) {
 	case nodes.SelectStmt:
 	case nodes.DeleteStmt:
 	case nodes.InsertStmt:
-		if err := validateInsertStmt(n); err != nil {
-			return nil, err
-		}
 	case nodes.UpdateStmt:
 	default:
 		return nil, errUnsupportedStatementType
@@ -440,13 +614,25 @@ func parseQuery(c core.Catalog, stmt nodes.Node, source string) (*Query, error)
 	if err != nil {
 		return nil, err
 	}
-	if err := validateFuncCall(&c, raw); err != nil {
-		return nil, err
-	}
 	name, cmd, err := ParseMetadata(strings.TrimSpace(rawSQL), CommentSyntaxDash)
 	if err != nil {
 		return nil, err
 	}
+	// Skipped queries aren't checked, so they may be unfinished
+	if cmd == ":skip" {
+		return nil, errSkippedQuery
+	}
+	if err := validateParamRef(stmt); err != nil {
+		return nil, err
+	}
+	if n, ok := raw.Stmt.(nodes.InsertStmt); ok {
+		if err := validateInsertStmt(n); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateFuncCall(&c, raw); err != nil {
+		return nil, err
+	}
 	if err := validateCmd(raw.Stmt, name, cmd); err != nil {
 		return nil, err
 	}
@@ -470,6 +656,11 @@ func parseQuery(c core.Catalog, stmt nodes.Node, source string) (*Query, error)
 	if err != nil {
 		return nil, err
 	}
+	if cmd == ":execmany" {
+		if err := validateExecMany(name, trimmed, len(params)); err != nil {
+			return nil, err
+		}
+	}
 
 	return &Query{
 		Cmd:      cmd,
@@ -478,9 +669,28 @@ func parseQuery(c core.Catalog, stmt nodes.Node, source string) (*Query, error)
 		Params:   params,
 		Columns:  cols,
 		SQL:      trimmed,
+		Tables:   referencedTables(rvs),
 	}, nil
 }
 
+// referencedTables returns the distinct relations named by rvs, in order
+func referencedTables(rvs []nodes.RangeVar) []core.FQN {
+	var tables []core.FQN
+	seen := map[core.FQN]bool{}
+	for i := range rvs {
+		fqn, err := catalog.ParseRange(&rvs[i])
+		if err != nil {
+			continue
+		}
+		fqn = core.FQN{Schema: fqn.Schema, Rel: fqn.Rel}
+		if !seen[fqn] {
+			seen[fqn] = true
+			tables = append(tables, fqn)
+		}
+	}
+	return tables
+}
+
 func stripComments(sql string) (string, []string, error) {
 	s := bufio.NewScanner(strings.NewReader(sql))
 	var lines, comments []string
@@ -518,8 +728,18 @@ func expand(c core.Catalog, raw nodes.RawStmt, sql string) (string, error) {
 	if len(list.Items) == 0 {
 		return sql, nil
 	}
+	// Statements nested in the query, like the terms of a recursive CTE,
+	// can refer to the query's CTEs
+	var with *nodes.WithClause
+	if stmt, ok := raw.Stmt.(nodes.SelectStmt); ok {
+		with = stmt.WithClause
+	}
 	var edits []edit
 	for _, item := range list.Items {
+		if stmt, ok := item.(nodes.SelectStmt); ok && stmt.WithClause == nil {
+			stmt.WithClause = with
+			item = stmt
+		}
 		edit, err := expandStmt(c, raw, item)
 		if err != nil {
 			return "", err
@@ -566,7 +786,7 @@ func expandStmt(c core.Catalog, raw nodes.RawStmt, node nodes.Node) ([]edit, err
 		for _, f := range ref.Fields.Items {
 			switch field := f.(type) {
 			case nodes.String:
-				parts = append(parts, field.Str)
+				parts = append(parts, quoteIdent(field.Str))
 			case nodes.A_Star:
 				parts = append(parts, "*")
 			default:
@@ -583,11 +803,9 @@ func expandStmt(c core.Catalog, raw nodes.RawStmt, node nodes.Node) ([]edit, err
 				if res.Name != nil {
 					cname = *res.Name
 				}
+				cname = quoteIdent(cname)
 				if scope != "" {
-					cname = scope + "." + cname
-				}
-				if postgres.IsReservedKeyword(cname) {
-					cname = "\"" + cname + "\""
+					cname = quoteIdent(scope) + "." + cname
 				}
 				cols = append(cols, cname)
 			}
@@ -601,6 +819,17 @@ func expandStmt(c core.Catalog, raw nodes.RawStmt, node nodes.Node) ([]edit, err
 	return edits, nil
 }
 
+var plainIdentPattern = regexp.MustCompile("^[a-z_][a-z0-9_$]*$")
+
+// quoteIdent double quotes an identifier that would otherwise be case folded
+// or parsed as a keyword, e.g. a quoted mixed-case column name like "userId"
+func quoteIdent(name string) string {
+	if postgres.IsReservedKeyword(name) || !plainIdentPattern.MatchString(name) {
+		return "\"" + strings.Replace(name, "\"", "\"\"", -1) + "\""
+	}
+	return name
+}
+
 func editQuery(raw string, a []edit) (string, error) {
 	if len(a) == 0 {
 		return raw, nil
@@ -638,10 +867,26 @@ func NewQueryCatalog(c core.Catalog, with *nodes.WithClause) QueryCatalog {
 	if with != nil {
 		for _, item := range with.Ctes.Items {
 			if cte, ok := item.(nodes.CommonTableExpr); ok {
-				cols, err := outputColumns(c, cte.Ctequery)
+				// The columns of a set operation come from its first term. For
+				// a recursive CTE that is the non-recursive term, which can't
+				// refer to the CTE itself.
+				query := cte.Ctequery
+				for {
+					sel, ok := query.(nodes.SelectStmt)
+					if !ok || sel.Larg == nil {
+						break
+					}
+					query = *sel.Larg
+				}
+				cols, err := outputColumns(c, query)
 				if err != nil {
 					panic(err.Error())
 				}
+				for i, item := range cte.Aliascolnames.Items {
+					if name, ok := item.(nodes.String); ok && i < len(cols) {
+						cols[i].Name = name.Str
+					}
+				}
 				ctes[*cte.Ctename] = core.Table{
 					Name:    *cte.Ctename,
 					Columns: cols,
@@ -696,10 +941,7 @@ func sourceTables(c core.Catalog, node nodes.Node) ([]core.Table, error) {
 		}
 	case nodes.SelectStmt:
 		with = n.WithClause
-		list = search(n.FromClause, func(node nodes.Node) bool {
-			_, ok := node.(nodes.RangeVar)
-			return ok
-		})
+		list = fromItems(n.FromClause)
 	default:
 		return nil, fmt.Errorf("sourceTables: unsupported node type: %T", n)
 	}
@@ -710,7 +952,7 @@ func sourceTables(c core.Catalog, node nodes.Node) ([]core.Table, error) {
 	for _, item := range list.Items {
 		switch n := item.(type) {
 		case nodes.RangeVar:
-			fqn, err := catalog.ParseRange(&n)
+			fqn, err := parseRange(c, &n)
 			if err != nil {
 				return nil, err
 			}
@@ -719,7 +961,25 @@ func sourceTables(c core.Catalog, node nodes.Node) ([]core.Table, error) {
 				cerr.Location = n.Location
 				return nil, *cerr
 			}
+			// Once aliased, a table may only be referred to by its alias
+			if n.Alias != nil && n.Alias.Aliasname != nil {
+				table.Name = *n.Alias.Aliasname
+			}
 			tables = append(tables, table)
+		case nodes.RangeSubselect:
+			// A derived table exposes the subquery's output columns under its alias
+			sub, ok := n.Subquery.(nodes.SelectStmt)
+			if !ok || n.Alias == nil || n.Alias.Aliasname == nil {
+				return nil, fmt.Errorf("sourceTable: unsupported subquery: %T", n.Subquery)
+			}
+			cols, err := outputColumns(c, sub)
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, core.Table{
+				Name:    *n.Alias.Aliasname,
+				Columns: cols,
+			})
 		default:
 			return nil, fmt.Errorf("sourceTable: unsupported list item type: %T", n)
 		}
@@ -727,6 +987,44 @@ func sourceTables(c core.Catalog, node nodes.Node) ([]core.Table, error) {
 	return tables, nil
 }
 
+// fromItems returns the tables and derived tables of a FROM clause. Joins are
+// flattened, but subqueries are left for the caller to resolve.
+func fromItems(from nodes.List) nodes.List {
+	var list nodes.List
+	var walk func(node nodes.Node)
+	walk = func(node nodes.Node) {
+		switch n := node.(type) {
+		case nodes.RangeVar, nodes.RangeSubselect:
+			list.Items = append(list.Items, n)
+		case nodes.JoinExpr:
+			walk(n.Larg)
+			walk(n.Rarg)
+		}
+	}
+	for _, item := range from.Items {
+		walk(item)
+	}
+	return list
+}
+
+// parseRange resolves an unqualified relation name to the first schema on the
+// catalog's search path that contains it
+func parseRange(c core.Catalog, rv *nodes.RangeVar) (core.FQN, error) {
+	fqn, err := catalog.ParseRange(rv)
+	if err != nil || rv.Schemaname != nil {
+		return fqn, err
+	}
+	for _, name := range c.SearchPath {
+		if schema, exists := c.Schemas[name]; exists {
+			if _, exists := schema.Tables[fqn.Rel]; exists {
+				fqn.Schema = name
+				return fqn, nil
+			}
+		}
+	}
+	return fqn, nil
+}
+
 func HasStarRef(cf nodes.ColumnRef) bool {
 	for _, item := range cf.Fields.Items {
 		if _, ok := item.(nodes.A_Star); ok {
@@ -776,42 +1074,49 @@ func outputColumns(c core.Catalog, node nodes.Node) ([]core.Column, error) {
 			if res.Name != nil {
 				name = *res.Name
 			}
+			op := join(n.Name, "")
 			switch {
-			case postgres.IsComparisonOperator(join(n.Name, "")):
+			case postgres.IsComparisonOperator(op), postgres.IsContainmentOperator(op):
 				// TODO: Generate a name for these operations
 				cols = append(cols, core.Column{Name: name, DataType: "bool", NotNull: true})
-			case postgres.IsMathematicalOperator(join(n.Name, "")):
+			case op == "->>", op == "#>>":
+				// The key or path may be missing, so the result is nullable
+				cols = append(cols, core.Column{Name: name, DataType: "text", NotNull: false})
+			case op == "->", op == "#>":
+				cols = append(cols, core.Column{Name: name, DataType: "jsonb", NotNull: false})
+			case postgres.IsMathematicalOperator(op):
 				// TODO: Generate correct numeric type
 				cols = append(cols, core.Column{Name: name, DataType: "pg_catalog.int4", NotNull: true})
 			default:
 				cols = append(cols, core.Column{Name: name, DataType: "any", NotNull: false})
 			}
 
-		case nodes.CaseExpr:
-			name := ""
-			if res.Name != nil {
-				name = *res.Name
-			}
-			// TODO: The TypeCase code has been copied from below. Instead, we need a recurse function to get the type of a node.
-			if tc, ok := n.Defresult.(nodes.TypeCast); ok {
-				if tc.TypeName == nil {
-					return nil, errors.New("no type name type cast")
+		case nodes.SubLink:
+			switch n.SubLinkType {
+			case nodes.EXISTS_SUBLINK:
+				name := "exists"
+				if res.Name != nil {
+					name = *res.Name
 				}
+				cols = append(cols, core.Column{Name: name, DataType: "bool", NotNull: true})
+			default:
 				name := ""
-				if ref, ok := tc.Arg.(nodes.ColumnRef); ok {
-					name = join(ref.Fields, "_")
-				}
 				if res.Name != nil {
 					name = *res.Name
 				}
-				// TODO Validate column names
-				col := catalog.ToColumn(tc.TypeName)
-				col.Name = name
-				cols = append(cols, col)
-			} else {
 				cols = append(cols, core.Column{Name: name, DataType: "any", NotNull: false})
 			}
 
+		case nodes.CaseExpr:
+			col, err := caseExprColumn(tables, n)
+			if err != nil {
+				return nil, err
+			}
+			if res.Name != nil {
+				col.Name = *res.Name
+			}
+			cols = append(cols, col)
+
 		case nodes.CoalesceExpr:
 			for _, arg := range n.Args.Items {
 				if ref, ok := arg.(nodes.ColumnRef); ok {
@@ -840,12 +1145,14 @@ func outputColumns(c core.Catalog, node nodes.Node) ([]core.Column, error) {
 							cname = *res.Name
 						}
 						cols = append(cols, core.Column{
-							Table:    t.ID,
-							Name:     cname,
-							Scope:    scope,
-							DataType: c.DataType,
-							NotNull:  c.NotNull,
-							IsArray:  c.IsArray,
+							Table:      t.ID,
+							Name:       cname,
+							Scope:      scope,
+							DataType:   c.DataType,
+							NotNull:    c.NotNull,
+							IsArray:    c.IsArray,
+							Comment:    c.Comment,
+							PrimaryKey: c.PrimaryKey,
 						})
 					}
 				}
@@ -890,6 +1197,12 @@ func outputColumns(c core.Catalog, node nodes.Node) ([]core.Column, error) {
 			// TODO Validate column names
 			col := catalog.ToColumn(n.TypeName)
 			col.Name = name
+			// Casting a column doesn't change its nullability
+			if ref, ok := n.Arg.(nodes.ColumnRef); ok && !HasStarRef(ref) {
+				if columns, err := outputColumnRefs(res, tables, ref); err == nil && len(columns) == 1 {
+					col.NotNull = columns[0].NotNull
+				}
+			}
 			cols = append(cols, col)
 
 		default:
@@ -904,6 +1217,96 @@ func outputColumns(c core.Catalog, node nodes.Node) ([]core.Column, error) {
 	return cols, nil
 }
 
+// caseExprColumn types a CASE expression from the results of its branches.
+// Branches with the same type resolve to that type, while untyped string
+// literals take the type of the other branches. The column is nullable if any
+// branch can be null, including a missing ELSE.
+func caseExprColumn(tables []core.Table, n nodes.CaseExpr) (core.Column, error) {
+	var results []nodes.Node
+	for _, item := range n.Args.Items {
+		if when, ok := item.(nodes.CaseWhen); ok {
+			results = append(results, when.Result)
+		}
+	}
+	results = append(results, n.Defresult)
+
+	var col *core.Column
+	notNull := true
+	untyped := false
+	for _, result := range results {
+		var branch core.Column
+		switch r := result.(type) {
+		case nil:
+			notNull = false
+			continue
+
+		case nodes.A_Const:
+			switch r.Val.(type) {
+			case nodes.Null:
+				notNull = false
+				continue
+			case nodes.String:
+				untyped = true
+				continue
+			case nodes.Integer:
+				branch = core.Column{DataType: "pg_catalog.int4", NotNull: true}
+			case nodes.Float:
+				branch = core.Column{DataType: "pg_catalog.numeric", NotNull: true}
+			default:
+				return core.Column{DataType: "any"}, nil
+			}
+
+		case nodes.ColumnRef:
+			if HasStarRef(r) {
+				return core.Column{DataType: "any"}, nil
+			}
+			columns, err := outputColumnRefs(nodes.ResTarget{}, tables, r)
+			if err != nil {
+				return core.Column{}, err
+			}
+			if len(columns) != 1 {
+				return core.Column{DataType: "any"}, nil
+			}
+			branch = columns[0]
+
+		case nodes.TypeCast:
+			if r.TypeName == nil {
+				return core.Column{}, errors.New("no type name type cast")
+			}
+			branch = catalog.ToColumn(r.TypeName)
+			if ref, ok := r.Arg.(nodes.ColumnRef); ok && !HasStarRef(ref) {
+				if columns, err := outputColumnRefs(nodes.ResTarget{}, tables, ref); err == nil && len(columns) == 1 {
+					branch.NotNull = columns[0].NotNull
+				}
+			}
+
+		default:
+			return core.Column{DataType: "any"}, nil
+		}
+
+		if !branch.NotNull {
+			notNull = false
+		}
+		if col == nil {
+			col = &core.Column{DataType: branch.DataType, IsArray: branch.IsArray}
+			continue
+		}
+		if col.DataType != branch.DataType || col.IsArray != branch.IsArray {
+			return core.Column{DataType: "any"}, nil
+		}
+	}
+
+	switch {
+	case col != nil:
+		col.NotNull = notNull
+		return *col, nil
+	case untyped:
+		return core.Column{DataType: "text", NotNull: notNull}, nil
+	default:
+		return core.Column{DataType: "any"}, nil
+	}
+}
+
 func outputColumnRefs(res nodes.ResTarget, tables []core.Table, node nodes.ColumnRef) ([]core.Column, error) {
 	parts := stringSlice(node.Fields)
 	var name, alias string
@@ -931,11 +1334,13 @@ func outputColumnRefs(res nodes.ResTarget, tables []core.Table, node nodes.Colum
 					cname = *res.Name
 				}
 				cols = append(cols, core.Column{
-					Table:    t.ID,
-					Name:     cname,
-					DataType: c.DataType,
-					NotNull:  c.NotNull,
-					IsArray:  c.IsArray,
+					Table:      t.ID,
+					Name:       cname,
+					DataType:   c.DataType,
+					NotNull:    c.NotNull,
+					IsArray:    c.IsArray,
+					Comment:    c.Comment,
+					PrimaryKey: c.PrimaryKey,
 				})
 			}
 		}
@@ -962,6 +1367,9 @@ type paramRef struct {
 	parent nodes.Node
 	rv     *nodes.RangeVar
 	ref    nodes.ParamRef
+
+	// True if the parameter is an array of values for the parent column
+	array bool
 }
 
 type paramSearch struct {
@@ -1011,6 +1419,10 @@ func (p paramSearch) Visit(node nodes.Node) Visitor {
 				}
 				ref, ok := target.Val.(nodes.ParamRef)
 				if !ok {
+					// INSERT ... SELECT unnest($1) inserts a row per element
+					if ref, ok := unnestParam(target.Val); ok && i < len(n.Cols.Items) {
+						p.refs[ref.Number] = paramRef{parent: n.Cols.Items[i], ref: ref, rv: p.rangeVar, array: true}
+					}
 					continue
 				}
 				// TODO: Out-of-bounds panic
@@ -1088,6 +1500,24 @@ func (p paramSearch) Visit(node nodes.Node) Visitor {
 	return p
 }
 
+// unnestParam returns the parameter in a call of the form unnest($1) or
+// unnest($1::type[])
+func unnestParam(node nodes.Node) (nodes.ParamRef, bool) {
+	fn, ok := node.(nodes.FuncCall)
+	if !ok || len(fn.Funcname.Items) == 0 || len(fn.Args.Items) != 1 {
+		return nodes.ParamRef{}, false
+	}
+	if name, ok := fn.Funcname.Items[len(fn.Funcname.Items)-1].(nodes.String); !ok || name.Str != "unnest" {
+		return nodes.ParamRef{}, false
+	}
+	arg := fn.Args.Items[0]
+	if cast, ok := arg.(nodes.TypeCast); ok {
+		arg = cast.Arg
+	}
+	ref, ok := arg.(nodes.ParamRef)
+	return ref, ok
+}
+
 func findParameters(root nodes.Node) []paramRef {
 	v := paramSearch{refs: map[int]paramRef{}}
 	Walk(v, root)
@@ -1117,6 +1547,15 @@ func search(root nodes.Node, f func(nodes.Node) bool) nodes.List {
 	return ns.list
 }
 
+func containsFQN(list []core.FQN, fqn core.FQN) bool {
+	for _, item := range list {
+		if item == fqn {
+			return true
+		}
+	}
+	return false
+}
+
 func resolveCatalogRefs(c core.Catalog, rvs []nodes.RangeVar, args []paramRef) ([]Parameter, error) {
 	aliasMap := map[string]core.FQN{}
 	// TODO: Deprecate defaultTable
@@ -1127,11 +1566,15 @@ func resolveCatalogRefs(c core.Catalog, rvs []nodes.RangeVar, args []paramRef) (
 		if rv.Relname == nil {
 			continue
 		}
-		fqn, err := catalog.ParseRange(&rv)
+		fqn, err := parseRange(c, &rv)
 		if err != nil {
 			return nil, err
 		}
-		tables = append(tables, fqn)
+		// A table referenced twice, e.g. by the terms of a recursive CTE,
+		// is searched once
+		if !containsFQN(tables, fqn) {
+			tables = append(tables, fqn)
+		}
 		if defaultTable == nil {
 			defaultTable = &fqn
 		}
@@ -1222,6 +1665,11 @@ func resolveCatalogRefs(c core.Catalog, rvs []nodes.RangeVar, args []paramRef) (
 				if alias != "" {
 					if original, ok := aliasMap[alias]; ok {
 						search = []core.FQN{original}
+					} else if alias == "excluded" && defaultTable != nil {
+						// The EXCLUDED pseudo-table of INSERT ... ON CONFLICT DO
+						// UPDATE has the columns of the insert target, which is
+						// always the first range var
+						search = []core.FQN{*defaultTable}
 					} else {
 						for _, fqn := range tables {
 							if fqn.Rel == alias {
@@ -1231,19 +1679,37 @@ func resolveCatalogRefs(c core.Catalog, rvs []nodes.RangeVar, args []paramRef) (
 					}
 				}
 
+				// The right-hand side of `col = ANY($1)` and `col = ALL($1)` is
+				// an array of the column's type
+				isArray := n.Kind == nodes.AEXPR_OP_ANY || n.Kind == nodes.AEXPR_OP_ALL
+
+				// The pattern of LIKE, ILIKE and SIMILAR TO is always text, even
+				// when the column being matched is cast from another type
+				isPattern := n.Kind == nodes.AEXPR_LIKE || n.Kind == nodes.AEXPR_ILIKE || n.Kind == nodes.AEXPR_SIMILAR
+
 				var found int
 				for _, table := range search {
 					if c, ok := typeMap[table.Schema][table.Rel][key]; ok {
 						found += 1
-						a = append(a, Parameter{
-							Number: ref.ref.Number,
-							Column: core.Column{
+						col := core.Column{
+							Name:       key,
+							DataType:   c.DataType,
+							NotNull:    c.NotNull,
+							IsArray:    c.IsArray || isArray,
+							Table:      c.Table,
+							Comment:    c.Comment,
+							PrimaryKey: c.PrimaryKey,
+						}
+						if isPattern {
+							col = core.Column{
 								Name:     key,
-								DataType: c.DataType,
+								DataType: "text",
 								NotNull:  c.NotNull,
-								IsArray:  c.IsArray,
-								Table:    c.Table,
-							},
+							}
+						}
+						a = append(a, Parameter{
+							Number: ref.ref.Number,
+							Column: col,
 						})
 					}
 				}
@@ -1324,11 +1790,12 @@ func resolveCatalogRefs(c core.Catalog, rvs []nodes.RangeVar, args []paramRef) (
 				a = append(a, Parameter{
 					Number: ref.ref.Number,
 					Column: core.Column{
-						Name:     key,
-						DataType: c.DataType,
-						NotNull:  c.NotNull,
-						IsArray:  c.IsArray,
-						Table:    c.Table,
+						Name:       key,
+						DataType:   c.DataType,
+						NotNull:    c.NotNull,
+						IsArray:    c.IsArray || ref.array,
+						Table:      c.Table,
+						PrimaryKey: c.PrimaryKey,
 					},
 				})
 			} else {
@@ -1,8 +1,12 @@
 package dinosql
 
 import (
+	"path/filepath"
 	"testing"
 
+	core "github.com/kyleconroy/sqlc/internal/pg"
+
+	"github.com/google/go-cmp/cmp"
 	pg "github.com/lfittl/pg_query_go"
 	nodes "github.com/lfittl/pg_query_go/nodes"
 )
@@ -144,3 +148,41 @@ func TestExpand(t *testing.T) {
 		t.Errorf("mismatch:\nexpected: %s\n  acutal: %s", expected, actual)
 	}
 }
+
+func TestMultipleQueryPaths(t *testing.T) {
+	tree, err := pg.Parse(`
+		CREATE TABLE authors (id serial primary key);
+		CREATE TABLE books (id serial primary key);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := core.NewCatalog()
+	if err := updateCatalog(&c, tree); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, paths := range []Paths{
+		{filepath.Join("testdata", "multi", "authors"), filepath.Join("testdata", "multi", "books")},
+		{filepath.Join("testdata", "multi", "*")},
+		{filepath.Join("testdata", "multi", "*", "*.sql"), filepath.Join("testdata", "multi", "authors", "authors.sql")},
+		{filepath.Join("testdata", "multi", "authors"), filepath.Join("testdata", "multi", "*")},
+		{filepath.Join("testdata", "multi", "*"), "." + string(filepath.Separator) + filepath.Join("testdata", "multi", "books", "books.sql")},
+	} {
+		result, err := ParseQueries(c, GenerateSettings{}, PackageSettings{Name: "db", Queries: paths})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, q := range result.Queries {
+			names = append(names, q.Name)
+		}
+		if diff := cmp.Diff([]string{"ListAuthors", "ListBooks"}, names); diff != "" {
+			t.Errorf("%v: query mismatch:\n%s", paths, diff)
+		}
+	}
+
+	if _, err := ParseQueries(c, GenerateSettings{}, PackageSettings{Queries: Paths{filepath.Join("testdata", "missing")}}); err == nil {
+		t.Errorf("expected an error for a missing path")
+	}
+}
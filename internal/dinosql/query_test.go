@@ -70,7 +70,7 @@ func TestQueries(t *testing.T) {
 			`,
 			Query{
 				Columns: []core.Column{
-					{Table: public("city"), Name: "slug", DataType: "text", NotNull: true},
+					{Table: public("city"), Name: "slug", DataType: "text", NotNull: true, PrimaryKey: true},
 					{Table: public("city"), Name: "name", DataType: "text", NotNull: true},
 				},
 			},
@@ -82,10 +82,10 @@ func TestQueries(t *testing.T) {
 			`,
 			Query{
 				Params: []Parameter{
-					{1, core.Column{Table: public("city"), Name: "slug", DataType: "text", NotNull: true}},
+					{1, core.Column{Table: public("city"), Name: "slug", DataType: "text", NotNull: true, PrimaryKey: true}},
 				},
 				Columns: []core.Column{
-					{Table: public("city"), Name: "slug", DataType: "text", NotNull: true},
+					{Table: public("city"), Name: "slug", DataType: "text", NotNull: true, PrimaryKey: true},
 					{Table: public("city"), Name: "name", DataType: "text", NotNull: true},
 				},
 			},
@@ -104,10 +104,10 @@ func TestQueries(t *testing.T) {
 			Query{
 				Params: []Parameter{
 					{1, core.Column{Table: public("city"), Name: "name", DataType: "text", NotNull: true}},
-					{2, core.Column{Table: public("city"), Name: "slug", DataType: "text", NotNull: true}},
+					{2, core.Column{Table: public("city"), Name: "slug", DataType: "text", NotNull: true, PrimaryKey: true}},
 				},
 				Columns: []core.Column{
-					{Table: public("city"), Name: "slug", DataType: "text", NotNull: true},
+					{Table: public("city"), Name: "slug", DataType: "text", NotNull: true, PrimaryKey: true},
 					{Table: public("city"), Name: "name", DataType: "text", NotNull: true},
 				},
 			},
@@ -119,7 +119,7 @@ func TestQueries(t *testing.T) {
 			`,
 			Query{
 				Params: []Parameter{
-					{1, core.Column{Table: public("city"), Name: "slug", DataType: "text", NotNull: true}},
+					{1, core.Column{Table: public("city"), Name: "slug", DataType: "text", NotNull: true, PrimaryKey: true}},
 					{2, core.Column{Table: public("city"), Name: "name", DataType: "text", NotNull: true}},
 				},
 			},
@@ -134,7 +134,7 @@ func TestQueries(t *testing.T) {
 			`,
 			Query{
 				Columns: []core.Column{
-					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true},
+					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true},
 					{Table: public("venue"), Name: "create_at", DataType: "pg_catalog.timestamp", NotNull: true},
 					{Table: public("venue"), Name: "status", DataType: "status", NotNull: true},
 					{Table: public("venue"), Name: "slug", DataType: "text", NotNull: true},
@@ -169,7 +169,7 @@ func TestQueries(t *testing.T) {
 			`,
 			Query{
 				Columns: []core.Column{
-					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true},
+					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true},
 					{Table: public("venue"), Name: "create_at", DataType: "pg_catalog.timestamp", NotNull: true},
 					{Table: public("venue"), Name: "status", DataType: "status", NotNull: true},
 					{Table: public("venue"), Name: "slug", DataType: "text", NotNull: true},
@@ -205,7 +205,7 @@ func TestQueries(t *testing.T) {
 			`,
 			Query{
 				Columns: []core.Column{
-					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true},
+					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true},
 				},
 				Params: []Parameter{
 					{1, core.Column{Table: public("venue"), NotNull: true, DataType: "text", Name: "slug"}},
@@ -226,7 +226,7 @@ func TestQueries(t *testing.T) {
 			`,
 			Query{
 				Columns: []core.Column{
-					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true},
+					{Table: public("venue"), Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true},
 				},
 				Params: []Parameter{
 					{1, core.Column{Table: public("venue"), DataType: "text", Name: "slug", NotNull: true}},
@@ -249,6 +249,25 @@ func TestQueries(t *testing.T) {
 				},
 			},
 		},
+		{
+			"window_count",
+			`
+			CREATE TABLE bar (id serial not null);
+			SELECT id, count(*) OVER() AS total, row_number() OVER (ORDER BY id)
+			FROM bar
+			LIMIT $1;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("bar"), Name: "id", DataType: "serial", NotNull: true},
+					{Name: "total", DataType: "bigint", NotNull: true},
+					{Name: "row_number", DataType: "bigint", NotNull: true},
+				},
+				Params: []Parameter{
+					{1, core.Column{Name: "limit", DataType: "integer", NotNull: true}},
+				},
+			},
+		},
 		{
 			"alias",
 			`
@@ -335,6 +354,43 @@ func TestQueries(t *testing.T) {
 				},
 			},
 		},
+		{
+			"cte_recursive",
+			`
+			CREATE TABLE categories (id serial primary key, parent_id integer, name text not null);
+			WITH RECURSIVE tree AS (
+				SELECT id, name FROM categories WHERE id = $1
+				UNION ALL
+				SELECT c.id, c.name FROM categories c JOIN tree ON c.parent_id = tree.id
+			)
+			SELECT tree.id, tree.name FROM tree;
+			`,
+			Query{
+				Params: []Parameter{
+					{1, core.Column{Table: public("categories"), Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true}},
+				},
+				Columns: []core.Column{
+					{Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true},
+					{Name: "name", DataType: "text", NotNull: true},
+				},
+			},
+		},
+		{
+			"cte_recursive_column_names",
+			`
+			WITH RECURSIVE series (n) AS (
+				SELECT 1::integer
+				UNION ALL
+				SELECT n + 1 FROM series WHERE n < 10
+			)
+			SELECT n FROM series;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "n", DataType: "pg_catalog.int4", NotNull: true},
+				},
+			},
+		},
 		{
 			"update_set",
 			`
@@ -377,6 +433,99 @@ func TestQueries(t *testing.T) {
 				},
 			},
 		},
+		{
+			"insert_select_unnest",
+			`
+			CREATE TABLE foo (name text not null, meta text);
+			INSERT INTO foo (name, meta)
+			SELECT unnest($1::text[]), unnest($2);
+			`,
+			Query{
+				Params: []Parameter{
+					{1, core.Column{Table: public("foo"), Name: "name", DataType: "text", NotNull: true, IsArray: true}},
+					{2, core.Column{Table: public("foo"), Name: "meta", DataType: "text", IsArray: true}},
+				},
+			},
+		},
+		{
+			"exists",
+			`
+			CREATE TABLE foo (id serial not null);
+			SELECT EXISTS(SELECT 1 FROM foo WHERE id = $1);
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "exists", DataType: "bool", NotNull: true},
+				},
+				Params: []Parameter{
+					{1, core.Column{Table: public("foo"), Name: "id", DataType: "serial", NotNull: true}},
+				},
+			},
+		},
+		{
+			"derived_table",
+			`
+			CREATE TABLE foo (id serial not null, name text not null, bio text);
+			SELECT t.label, bio FROM (SELECT name AS label, bio FROM foo) AS t;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "label", DataType: "text", NotNull: true},
+					{Name: "bio", DataType: "text"},
+				},
+			},
+		},
+		{
+			"derived_table_star",
+			`
+			CREATE TABLE foo (id serial not null, name text not null);
+			SELECT * FROM (SELECT name AS x FROM foo) t;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "x", DataType: "text", NotNull: true},
+				},
+			},
+		},
+		{
+			"upsert_returning",
+			`
+			CREATE TABLE foo (id serial primary key, name text not null, bio text);
+			INSERT INTO foo (id, name, bio) VALUES ($1, $2, $3)
+			ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, bio = $4
+			WHERE excluded.name <> $5
+			RETURNING id, name;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("foo"), Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true},
+					{Table: public("foo"), Name: "name", DataType: "text", NotNull: true},
+				},
+				Params: []Parameter{
+					{1, core.Column{Table: public("foo"), Name: "id", DataType: "serial", NotNull: true, PrimaryKey: true}},
+					{2, core.Column{Table: public("foo"), Name: "name", DataType: "text", NotNull: true}},
+					{3, core.Column{Table: public("foo"), Name: "bio", DataType: "text"}},
+					{4, core.Column{Table: public("foo"), Name: "bio", DataType: "text"}},
+					{5, core.Column{Table: public("foo"), Name: "name", DataType: "text", NotNull: true}},
+				},
+			},
+		},
+		{
+			"upsert_select_excluded",
+			`
+			CREATE TABLE bar (name text not null, ready bool not null);
+			CREATE TABLE foo (name text primary key, ready bool not null);
+			INSERT INTO foo (name, ready)
+			SELECT name, ready FROM bar
+			ON CONFLICT (name) DO UPDATE SET ready = EXCLUDED.ready
+			WHERE excluded.ready = $1;
+			`,
+			Query{
+				Params: []Parameter{
+					{1, core.Column{Table: public("foo"), Name: "ready", DataType: "bool", NotNull: true}},
+				},
+			},
+		},
 		{
 			"as",
 			`
@@ -427,6 +576,111 @@ func TestQueries(t *testing.T) {
 				},
 			},
 		},
+		{
+			"select nullable column cast",
+			`
+			CREATE TABLE foo (bar integer);
+			SELECT bar::text FROM foo;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "bar", DataType: "text"},
+				},
+			},
+		},
+		{
+			"select param cast",
+			`
+			SELECT $1::text AS foo;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "foo", DataType: "text", NotNull: true},
+				},
+				Params: []Parameter{
+					{1, core.Column{DataType: "text", NotNull: true}},
+				},
+			},
+		},
+		{
+			"where param cast",
+			`
+			CREATE TABLE foo (bar text not null);
+			SELECT bar FROM foo WHERE length(bar) > $1::int;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("foo"), Name: "bar", DataType: "text", NotNull: true},
+				},
+				Params: []Parameter{
+					{1, core.Column{DataType: "pg_catalog.int4", NotNull: true}},
+				},
+			},
+		},
+		{
+			"distinct-on",
+			`
+			CREATE TABLE foo (id integer not null, bar_id integer not null, name text);
+			SELECT DISTINCT ON (bar_id) * FROM foo ORDER BY bar_id, id;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("foo"), Name: "id", DataType: "pg_catalog.int4", NotNull: true},
+					{Table: public("foo"), Name: "bar_id", DataType: "pg_catalog.int4", NotNull: true},
+					{Table: public("foo"), Name: "name", DataType: "text"},
+				},
+				SQL: "SELECT DISTINCT ON (bar_id) id, bar_id, name FROM foo ORDER BY bar_id, id",
+			},
+		},
+		{
+			"bool-param",
+			`
+			CREATE TABLE foo (name text not null, active boolean not null);
+			SELECT name FROM foo WHERE active = $1;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("foo"), Name: "name", DataType: "text", NotNull: true},
+				},
+				Params: []Parameter{
+					{1, core.Column{Table: public("foo"), Name: "active", DataType: "pg_catalog.bool", NotNull: true}},
+				},
+			},
+		},
+		{
+			"quoted-identifiers",
+			`
+			CREATE TABLE "Users" ("userId" text not null, name text);
+			SELECT * FROM "Users" WHERE "userId" = $1;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("Users"), Name: "userId", DataType: "text", NotNull: true},
+					{Table: public("Users"), Name: "name", DataType: "text"},
+				},
+				Params: []Parameter{
+					{1, core.Column{Table: public("Users"), Name: "userId", DataType: "text", NotNull: true}},
+				},
+				SQL: `SELECT "userId", name FROM "Users" WHERE "userId" = $1`,
+			},
+		},
+		{
+			"like",
+			`
+			CREATE TABLE foo (id integer not null, name text not null);
+			SELECT name FROM foo WHERE name LIKE $1 OR name ILIKE $2 OR id::text LIKE $3;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("foo"), Name: "name", DataType: "text", NotNull: true},
+				},
+				Params: []Parameter{
+					{1, core.Column{Name: "name", DataType: "text", NotNull: true}},
+					{2, core.Column{Name: "name", DataType: "text", NotNull: true}},
+					{3, core.Column{Name: "id", DataType: "text", NotNull: true}},
+				},
+			},
+		},
 		{
 			"limit",
 			`
@@ -736,6 +990,161 @@ func TestQueries(t *testing.T) {
 				},
 			},
 		},
+		{
+			"view",
+			`
+			CREATE TABLE foo (id serial primary key, name text not null, bio text, tags text[] not null);
+			CREATE VIEW foo_names AS SELECT id, name, bio, tags FROM foo;
+			SELECT * FROM foo_names WHERE id = $1;
+			`,
+			Query{
+				Params: []Parameter{
+					{1, core.Column{Table: public("foo_names"), Name: "id", DataType: "serial", NotNull: true}},
+				},
+				Columns: []core.Column{
+					{Table: public("foo_names"), Name: "id", DataType: "serial", NotNull: true},
+					{Table: public("foo_names"), Name: "name", DataType: "text", NotNull: true},
+					{Table: public("foo_names"), Name: "bio", DataType: "text"},
+					{Table: public("foo_names"), Name: "tags", DataType: "text", NotNull: true, IsArray: true},
+				},
+			},
+		},
+		{
+			"view-column-names",
+			`
+			CREATE TABLE foo (id serial primary key, name text not null);
+			CREATE VIEW foo_names (foo_id) AS SELECT id, upper(name) display_name FROM foo;
+			SELECT foo_id, display_name FROM foo_names;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("foo_names"), Name: "foo_id", DataType: "serial", NotNull: true},
+					{Table: public("foo_names"), Name: "display_name", DataType: "text", NotNull: true},
+				},
+			},
+		},
+		{
+			"materialized-view",
+			`
+			CREATE TABLE foo (id serial primary key, name text not null, bio text);
+			CREATE MATERIALIZED VIEW foo_bios (foo_id) AS SELECT id, bio FROM foo WITH NO DATA;
+			SELECT * FROM foo_bios;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Table: public("foo_bios"), Name: "foo_id", DataType: "serial", NotNull: true},
+					{Table: public("foo_bios"), Name: "bio", DataType: "text"},
+				},
+			},
+		},
+		{
+			"case-stmt-text",
+			`
+			CREATE TABLE foo (name text not null, nickname text not null);
+			SELECT CASE
+			  WHEN nickname <> '' THEN nickname
+			  ELSE name
+			END display_name
+			FROM foo;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "display_name", DataType: "text", NotNull: true},
+				},
+			},
+		},
+		{
+			"case-stmt-nullable-branch",
+			`
+			CREATE TABLE foo (name text not null, nickname text);
+			SELECT CASE
+			  WHEN nickname IS NOT NULL THEN nickname
+			  ELSE 'anonymous'
+			END display_name
+			FROM foo;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "display_name", DataType: "text", NotNull: false},
+				},
+			},
+		},
+		{
+			"case-stmt-no-else",
+			`
+			CREATE TABLE foo (name text not null);
+			SELECT CASE WHEN name <> '' THEN name END display_name
+			FROM foo;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "display_name", DataType: "text", NotNull: false},
+				},
+			},
+		},
+		{
+			"case-stmt-mixed-types",
+			`
+			CREATE TABLE foo (id integer not null, name text not null);
+			SELECT CASE WHEN id > 1 THEN name ELSE id END display_name
+			FROM foo;
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "display_name", DataType: "any"},
+				},
+			},
+		},
+		{
+			"referenced-tables",
+			`
+			CREATE SCHEMA audit;
+			CREATE TABLE foo (id integer not null);
+			CREATE TABLE audit.bar (id integer not null, foo_id integer not null);
+			SELECT foo.id FROM foo JOIN audit.bar ON bar.foo_id = foo.id
+			WHERE foo.id IN (SELECT foo_id FROM audit.bar);
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "id", DataType: "pg_catalog.int4", NotNull: true, Table: core.FQN{Schema: "public", Rel: "foo"}},
+				},
+				Tables: []core.FQN{
+					{Schema: "public", Rel: "foo"},
+					{Schema: "audit", Rel: "bar"},
+				},
+			},
+		},
+		{
+			"date_trunc",
+			`
+			CREATE TABLE events (id integer not null, created_at timestamptz not null);
+			SELECT id FROM events WHERE date_trunc('day', created_at) = date_trunc('day', $1);
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "id", DataType: "pg_catalog.int4", NotNull: true, Table: public("events")},
+				},
+				Params: []Parameter{
+					{1, core.Column{Name: "source", DataType: "pg_catalog.timestamptz", NotNull: true}},
+				},
+			},
+		},
+		{
+			"jsonb-operators",
+			`
+			CREATE TABLE foo (id integer not null, data jsonb not null);
+			SELECT data->>'name' AS name, data->'tags' AS tags, data ? 'admin' AS admin
+			FROM foo
+			WHERE data @> '{"active": true}' AND data <@ '{}';
+			`,
+			Query{
+				Columns: []core.Column{
+					{Name: "name", DataType: "text"},
+					{Name: "tags", DataType: "jsonb"},
+					{Name: "admin", DataType: "bool", NotNull: true},
+				},
+			},
+		},
 		{
 			"join-text-array",
 			`
@@ -869,6 +1278,36 @@ func TestQueries(t *testing.T) {
 				},
 			},
 		},
+		{
+			"any",
+			`
+			CREATE TABLE foo (id integer not null);
+			SELECT id FROM foo WHERE id = ANY($1);
+			`,
+			Query{
+				Params: []Parameter{
+					{1, core.Column{Table: public("foo"), Name: "id", DataType: "pg_catalog.int4", NotNull: true, IsArray: true}},
+				},
+				Columns: []core.Column{
+					{Table: public("foo"), Name: "id", DataType: "pg_catalog.int4", NotNull: true},
+				},
+			},
+		},
+		{
+			"all-cast",
+			`
+			CREATE TABLE foo (id integer not null);
+			SELECT id FROM foo WHERE id <> ALL($1::int[]);
+			`,
+			Query{
+				Params: []Parameter{
+					{1, core.Column{DataType: "pg_catalog.int4", NotNull: true, IsArray: true}},
+				},
+				Columns: []core.Column{
+					{Table: public("foo"), Name: "id", DataType: "pg_catalog.int4", NotNull: true},
+				},
+			},
+		},
 		{
 			"pg_advisory_xact_lock",
 			`
@@ -894,6 +1333,9 @@ func TestQueries(t *testing.T) {
 			if test.query.SQL == "" {
 				q.SQL = ""
 			}
+			if test.query.Tables == nil {
+				q.Tables = nil
+			}
 			if diff := cmp.Diff(test.query, q); diff != "" {
 				t.Errorf("query mismatch: \n%s", diff)
 			}
@@ -919,6 +1361,7 @@ func TestComparisonOperators(t *testing.T) {
 				Columns: []core.Column{
 					{Name: "", DataType: "bool", NotNull: true},
 				},
+				Tables: []core.FQN{{Schema: "public", Rel: "bar"}},
 			}
 			if diff := cmp.Diff(expected, q); diff != "" {
 				t.Errorf("query mismatch: \n%s", diff)
@@ -1006,6 +1449,64 @@ func TestInvalidQueries(t *testing.T) {
 			`,
 			`INSERT has more expressions than target columns`,
 		},
+		{
+			`
+			CREATE TABLE foo (id text not null);
+			-- name: UpdateFoos :execmany
+			UPDATE foo SET id = $1;
+			`,
+			`query "UpdateFoos" specifies parameter ":execmany" without being an INSERT statement`,
+		},
+		{
+			`
+			CREATE TABLE foo (id text not null);
+			-- name: InsertFoos :execmany
+			INSERT INTO foo (id) VALUES ($1), ($2);
+			`,
+			`query "InsertFoos" specifies parameter ":execmany" without containing a single VALUES row`,
+		},
+		{
+			`
+			CREATE TABLE foo (id text not null);
+			-- name: InsertFoos :execmany
+			INSERT INTO foo (id) VALUES ($1) RETURNING id;
+			`,
+			`query "InsertFoos" specifies parameter ":execmany" but contains a RETURNING clause`,
+		},
+		{
+			`
+			CREATE TABLE foo (id text not null, name text);
+			-- name: InsertFoos :execmany
+			INSERT INTO foo (id) VALUES ($1) ON CONFLICT (id) DO UPDATE SET name = $2;
+			`,
+			`query "InsertFoos" specifies parameter ":execmany" with parameters outside of its VALUES row`,
+		},
+		{
+			`
+			CREATE TABLE foo (id text not null);
+			CREATE VIEW foo_ids AS SELECT id FROM foo;
+			DROP VIEW foo_ids;
+			SELECT id FROM foo_ids;
+			`,
+			`relation "foo_ids" does not exist`,
+		},
+		{
+			`
+			CREATE TABLE foo (id text not null);
+			CREATE VIEW foo_ids (id, name) AS SELECT id FROM foo;
+			SELECT id FROM foo_ids;
+			`,
+			"CREATE VIEW specifies more column names than columns",
+		},
+		{
+			`
+			CREATE TABLE foo (id text not null);
+			CREATE MATERIALIZED VIEW foo_ids AS SELECT id FROM foo;
+			DROP MATERIALIZED VIEW foo_ids;
+			SELECT id FROM foo_ids;
+			`,
+			`relation "foo_ids" does not exist`,
+		},
 	} {
 		test := tc
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -1019,3 +1520,50 @@ func TestInvalidQueries(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchPath(t *testing.T) {
+	stmt := `
+		CREATE SCHEMA other;
+		CREATE TABLE foo (id text not null);
+		CREATE TABLE other.foo (id integer not null);
+		SELECT id FROM foo;
+	`
+	tree, err := pg.Parse(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := core.NewCatalog()
+	if err := updateCatalog(&c, tree); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path   []string
+		column core.Column
+	}{
+		{
+			nil,
+			core.Column{Table: public("foo"), Name: "id", DataType: "text", NotNull: true},
+		},
+		{
+			[]string{"public"},
+			core.Column{Table: public("foo"), Name: "id", DataType: "text", NotNull: true},
+		},
+		{
+			[]string{"other", "public"},
+			core.Column{Table: core.FQN{Schema: "other", Rel: "foo"}, Name: "id", DataType: "pg_catalog.int4", NotNull: true},
+		},
+	} {
+		test := tc
+		t.Run(fmt.Sprintf("%v", test.path), func(t *testing.T) {
+			c.SearchPath = test.path
+			q, err := parseQuery(c, tree.Statements[len(tree.Statements)-1], stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff([]core.Column{test.column}, q.Columns); diff != "" {
+				t.Errorf("columns mismatch: \n%s", diff)
+			}
+		})
+	}
+}
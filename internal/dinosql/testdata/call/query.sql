@@ -0,0 +1,5 @@
+-- name: ListAuthors :many
+SELECT 1;
+
+-- name: ArchiveAuthor :exec
+CALL archive_author($1);
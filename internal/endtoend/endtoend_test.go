@@ -44,7 +44,7 @@ func TestCodeGeneration(t *testing.T) {
 					fmt.Printf("%#v\n", err)
 					t.Fatal(err)
 				}
-				q, err := dinosql.ParseQueries(c, pkg)
+				q, err := dinosql.ParseQueries(c, conf, pkg)
 				if err != nil {
 					t.Fatal(err)
 				}
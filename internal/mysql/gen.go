@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"fmt"
+	"go/token"
 	"log"
 	"sort"
 	"strings"
@@ -27,8 +28,8 @@ func (r *Result) PkgName() string {
 // Enums generates parser-agnostic GoEnum types
 func (r *Result) Enums(settings dinosql.GenerateSettings) []dinosql.GoEnum {
 	var enums []dinosql.GoEnum
-	for _, table := range r.Schema.tables {
-		for _, col := range table {
+	for _, tableName := range r.Schema.tableNames() {
+		for _, col := range r.Schema.tables[tableName] {
 			if col.Type.Type == "enum" {
 				constants := []dinosql.GoConstant{}
 				enumName := enumNameFromColDef(col, settings)
@@ -54,6 +55,11 @@ func (r *Result) Enums(settings dinosql.GenerateSettings) []dinosql.GoEnum {
 	return enums
 }
 
+// CheckEnums returns nil, as CHECK constraints aren't parsed for MySQL
+func (r *Result) CheckEnums(settings dinosql.GenerateSettings) []dinosql.GoEnum {
+	return nil
+}
+
 func stripInnerQuotes(identifier string) string {
 	return strings.Replace(identifier, "'", "", 2)
 }
@@ -66,7 +72,11 @@ func enumNameFromColDef(col *sqlparser.ColumnDefinition, settings dinosql.Genera
 // Structs marshels each query into a go struct for generation
 func (r *Result) Structs(settings dinosql.GenerateSettings) []dinosql.GoStruct {
 	var structs []dinosql.GoStruct
-	for tableName, cols := range r.Schema.tables {
+	for _, tableName := range r.Schema.tableNames() {
+		if settings.PackageMap[r.PkgName()].ExcludesTable("", tableName) {
+			continue
+		}
+		cols := r.Schema.tables[tableName]
 		s := dinosql.GoStruct{
 			Name:  inflection.Singular(dinosql.StructName(tableName, settings)),
 			Table: core.FQN{tableName, "", ""}, // TODO: Complete hack. Only need for equality check to see if struct can be reused between queries
@@ -76,7 +86,7 @@ func (r *Result) Structs(settings dinosql.GenerateSettings) []dinosql.GoStruct {
 			s.Fields = append(s.Fields, dinosql.GoField{
 				Name:    dinosql.StructName(col.Name.String(), settings),
 				Type:    goTypeCol(col, settings),
-				Tags:    map[string]string{"json:": col.Name.String()},
+				Tags:    dinosql.StructTags(col.Name.String(), settings.PackageMap[r.PkgName()]),
 				Comment: "",
 			})
 		}
@@ -102,24 +112,20 @@ func (r *Result) GoQueries(settings dinosql.GenerateSettings) []dinosql.GoQuery
 			continue
 		}
 
+		methodName, constantName := dinosql.QueryIdentifiers(query.Name, settings.PackageMap[r.PkgName()])
 		gq := dinosql.GoQuery{
 			Cmd:          query.Cmd,
-			ConstantName: dinosql.LowerTitle(query.Name),
-			FieldName:    dinosql.LowerTitle(query.Name) + "Stmt",
-			MethodName:   query.Name,
+			ConstantName: constantName,
+			FieldName:    dinosql.QueryFieldName(query.Name, settings.PackageMap[r.PkgName()]),
+			MethodName:   methodName,
 			SourceName:   query.Filename,
 			SQL:          query.SQL,
 			// Comments:     query.Comments,
 		}
 
-		if len(query.Params) == 1 {
-			p := query.Params[0]
-			gq.Arg = dinosql.GoQueryValue{
-				Name: p.Name,
-				Typ:  p.Typ,
-			}
-		} else if len(query.Params) > 1 {
-
+		switch limit := settings.PackageMap[r.PkgName()].ParamsStructLimit(); {
+		case len(query.Params) == 0:
+		case len(query.Params) >= limit:
 			structInfo := make([]structParams, len(query.Params))
 			for i := range query.Params {
 				structInfo[i] = structParams{
@@ -131,7 +137,18 @@ func (r *Result) GoQueries(settings dinosql.GenerateSettings) []dinosql.GoQuery
 			gq.Arg = dinosql.GoQueryValue{
 				Emit:   true,
 				Name:   "arg",
-				Struct: r.columnsToStruct(gq.MethodName+"Params", structInfo, settings),
+				Struct: r.columnsToStruct(settings.PackageMap[r.PkgName()].ParamsStructName(gq.MethodName), structInfo, settings),
+			}
+		case len(query.Params) == 1:
+			p := query.Params[0]
+			gq.Arg = dinosql.GoQueryValue{
+				Name: p.Name,
+				Typ:  p.Typ,
+			}
+		default:
+			gq.Arg = dinosql.GoQueryValue{
+				Positional: true,
+				Struct:     paramsToPositional(query.Params),
 			}
 		}
 
@@ -176,7 +193,7 @@ func (r *Result) GoQueries(settings dinosql.GenerateSettings) []dinosql.GoQuery
 						goType:       goTypeCol(query.Columns[i].ColumnDefinition, settings),
 					}
 				}
-				gs = r.columnsToStruct(gq.MethodName+"Row", structInfo, settings)
+				gs = r.columnsToStruct(settings.PackageMap[r.PkgName()].RowStructName(gq.MethodName), structInfo, settings)
 				emit = true
 			}
 			gq.Ret = dinosql.GoQueryValue{
@@ -185,6 +202,7 @@ func (r *Result) GoQueries(settings dinosql.GenerateSettings) []dinosql.GoQuery
 				Struct: gs,
 			}
 		}
+		gq.RetPointer = gq.Cmd == ":one" && gq.Ret.IsStruct() && settings.PackageMap[r.PkgName()].EmitResultPointers
 
 		qs = append(qs, gq)
 	}
@@ -192,6 +210,25 @@ func (r *Result) GoQueries(settings dinosql.GenerateSettings) []dinosql.GoQuery
 	return qs
 }
 
+// paramsToPositional names each parameter as a function argument, adding a
+// numeric suffix when two parameters share a name
+func paramsToPositional(params []*Param) *dinosql.GoStruct {
+	gs := dinosql.GoStruct{}
+	seen := map[string]int{}
+	for _, p := range params {
+		name := argName(p.Name)
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, seen[name])
+		}
+		gs.Fields = append(gs.Fields, dinosql.GoField{
+			Name: name,
+			Type: p.Typ,
+		})
+	}
+	return &gs
+}
+
 type structParams struct {
 	originalName string
 	goType       string
@@ -214,7 +251,7 @@ func (r *Result) columnsToStruct(name string, items []structParams, settings din
 		gs.Fields = append(gs.Fields, dinosql.GoField{
 			Name: fieldName,
 			Type: typ,
-			Tags: map[string]string{"json:": tagName},
+			Tags: dinosql.StructTags(tagName, settings.PackageMap[r.PkgName()]),
 		})
 		seen[name]++
 	}
@@ -222,6 +259,7 @@ func (r *Result) columnsToStruct(name string, items []structParams, settings din
 }
 
 func goTypeCol(col *sqlparser.ColumnDefinition, settings dinosql.GenerateSettings) string {
+	unsigned := bool(col.Type.Unsigned)
 	switch t := col.Type.Type; {
 	case "varchar" == t, "text" == t, "char" == t,
 		"tinytext" == t, "mediumtext" == t, "longtext" == t:
@@ -229,6 +267,28 @@ func goTypeCol(col *sqlparser.ColumnDefinition, settings dinosql.GenerateSetting
 			return "string"
 		}
 		return "sql.NullString"
+	case unsigned && ("int" == t || "integer" == t || "mediumint" == t):
+		if col.Type.NotNull {
+			return "uint32"
+		}
+		return "sql.NullInt64"
+	case unsigned && "bigint" == t:
+		if col.Type.NotNull {
+			return "uint64"
+		}
+		// sql.NullInt64 can't hold values above math.MaxInt64; scan them as
+		// their decimal text instead
+		return "sql.NullString"
+	case unsigned && "smallint" == t:
+		if col.Type.NotNull {
+			return "uint16"
+		}
+		return "sql.NullInt64"
+	case unsigned && "tinyint" == t:
+		if col.Type.NotNull {
+			return "uint8"
+		}
+		return "sql.NullInt64"
 	case "int" == t, "integer" == t, t == "smallint",
 		t == "tinyint", "mediumint" == t, "bigint" == t, "year" == t:
 		if col.Type.NotNull {
@@ -279,5 +339,9 @@ func argName(name string) string {
 			out += strings.Title(p)
 		}
 	}
+	// A column named e.g. type can't be used as is for an argument
+	if token.Lookup(out).IsKeyword() {
+		out += "_"
+	}
 	return out
 }
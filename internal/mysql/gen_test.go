@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -26,6 +27,10 @@ func TestArgName(t *testing.T) {
 			input:  "get_all_",
 			output: "getAll",
 		},
+		{
+			input:  "type",
+			output: "type_",
+		},
 	}
 
 	for _, tc := range tcase {
@@ -128,3 +133,157 @@ func TestStructs(t *testing.T) {
 		}
 	}
 }
+
+func TestEnumOrder(t *testing.T) {
+	schema := NewSchema()
+	for _, ddl := range []string{
+		"CREATE TABLE zoos (state ENUM('open', 'closed') NOT NULL)",
+		"CREATE TABLE animals (kind ENUM('cat', 'dog') NOT NULL)",
+		"CREATE TABLE keepers (shift ENUM('day', 'night') NOT NULL)",
+	} {
+		stmt, err := sqlparser.Parse(ddl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema.Add(stmt.(*sqlparser.DDL))
+	}
+	for i := 0; i < 20; i++ {
+		var names []string
+		for _, e := range (&Result{Schema: schema}).Enums(mockSettings) {
+			names = append(names, e.Name)
+		}
+		if diff := cmp.Diff([]string{"KindType", "ShiftType", "StateType"}, names); diff != "" {
+			t.Fatalf("enum order differs:\n%s", diff)
+		}
+	}
+}
+
+func TestUnsignedTypes(t *testing.T) {
+	for _, tc := range []struct {
+		typ     string
+		notNull bool
+		output  string
+	}{
+		{"int", true, "uint32"},
+		{"integer", true, "uint32"},
+		{"bigint", true, "uint64"},
+		{"smallint", true, "uint16"},
+		{"tinyint", true, "uint8"},
+		{"int", false, "sql.NullInt64"},
+		{"bigint", false, "sql.NullString"},
+		{"smallint", false, "sql.NullInt64"},
+	} {
+		col := &sqlparser.ColumnDefinition{
+			Name: sqlparser.NewColIdent("id"),
+			Type: sqlparser.ColumnType{
+				Type:     tc.typ,
+				NotNull:  sqlparser.BoolVal(tc.notNull),
+				Unsigned: true,
+			},
+		}
+		if diff := cmp.Diff(tc.output, goTypeCol(col, mockSettings)); diff != "" {
+			t.Errorf("unsigned %s: %s", tc.typ, diff)
+		}
+	}
+}
+
+// generatePackage generates the code for queries against mockSchema
+func generatePackage(t *testing.T, queries string, pkg dinosql.PackageSettings) map[string]string {
+	t.Helper()
+
+	pkg.Name = "db"
+	settings := dinosql.GenerateSettings{
+		Version:  "1",
+		Packages: []dinosql.PackageSettings{pkg},
+	}
+	if err := settings.PopulatePkgMap(); err != nil {
+		t.Fatal(err)
+	}
+	qs, err := parseContents("query.sql", queries, mockSchema, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := dinosql.Generate(&Result{Queries: qs, Schema: mockSchema, packageName: pkg.Name}, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestUnexportedStmtAccessors(t *testing.T) {
+	output := generatePackage(t, `
+/* name: GetUser :one */
+SELECT first_name FROM users WHERE id = ?;
+`, dinosql.PackageSettings{EmitPreparedQueries: true, EmitStmtAccessors: true, EmitUnexported: true})
+
+	for _, expected := range []string{
+		"getUserPrepared *sql.Stmt",
+		"func (q *Queries) getUserStmt() *sql.Stmt {\n\treturn q.getUserPrepared\n}",
+	} {
+		if !strings.Contains(output["db.go"], expected) {
+			t.Errorf("db.go does not contain %q:\n%s", expected, output["db.go"])
+		}
+	}
+}
+
+func TestQueryParameterLimit(t *testing.T) {
+	queries := `
+/* name: ListUsers :many */
+SELECT first_name FROM users WHERE age > ? AND id > ? AND last_name = ?;
+`
+	output := generatePackage(t, queries, dinosql.PackageSettings{QueryParameterLimit: 4})
+	expected := "func (q *Queries) ListUsers(ctx context.Context, age int, id int, lastName sql.NullString) ([]string, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+
+	output = generatePackage(t, queries, dinosql.PackageSettings{QueryParameterLimit: 3})
+	expected = "func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]string, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
+
+func TestStructTags(t *testing.T) {
+	queries := `
+/* name: ListUsers :many */
+SELECT first_name, last_name FROM users;
+`
+	for _, tc := range []struct {
+		pkg      dinosql.PackageSettings
+		expected string
+	}{
+		{
+			dinosql.PackageSettings{EmitJSONTags: true, EmitDBTags: true},
+			"`db:\"first_name\" json:\"first_name\"`",
+		},
+		{
+			dinosql.PackageSettings{EmitDBTags: true},
+			"`db:\"first_name\"`",
+		},
+		{
+			dinosql.PackageSettings{EmitJSONTags: true, JSONTagsCaseStyle: "camel"},
+			"`json:\"firstName\"`",
+		},
+	} {
+		output := generatePackage(t, queries, tc.pkg)
+		if !strings.Contains(output["query.sql.go"], tc.expected) {
+			t.Errorf("query.sql.go does not contain %q:\n%s", tc.expected, output["query.sql.go"])
+		}
+		if !strings.Contains(output["models.go"], tc.expected) {
+			t.Errorf("models.go does not contain %q:\n%s", tc.expected, output["models.go"])
+		}
+	}
+}
+
+func TestEmitResultPointers(t *testing.T) {
+	output := generatePackage(t, `
+/* name: GetUser :one */
+SELECT first_name, last_name FROM users WHERE id = ?;
+`, dinosql.PackageSettings{EmitResultPointers: true})
+
+	expected := "func (q *Queries) GetUser(ctx context.Context, id int) (*GetUserRow, error) {"
+	if !strings.Contains(output["query.sql.go"], expected) {
+		t.Errorf("query.sql.go does not contain %q:\n%s", expected, output["query.sql.go"])
+	}
+}
@@ -29,8 +29,8 @@ type Column struct {
 	Table string
 }
 
-func parsePath(sqlPath string, inPkg string, s *Schema, settings dinosql.GenerateSettings) (*Result, error) {
-	files, err := dinosql.ReadSQLFiles(sqlPath)
+func parsePath(sqlPaths []string, inPkg string, s *Schema, settings dinosql.GenerateSettings) (*Result, error) {
+	files, err := dinosql.ReadSQLFiles(sqlPaths...)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +92,7 @@ func parseContents(filename, contents string, s *Schema, settings dinosql.Genera
 			return nil, sqlparser.PositionedErr{Err: err.Error(), Pos: start, Near: nil}
 		}
 		start = t.Position
-		if result == nil {
+		if result == nil || result.Cmd == ":skip" {
 			continue
 		}
 		result.Filename = filepath.Base(filename)
@@ -154,6 +154,9 @@ func (q *Query) parseNameAndCmd() error {
 	} else if name == "" || cmd == "" {
 		return fmt.Errorf("failed to parse query leading comment")
 	}
+	if cmd == ":execmany" {
+		return fmt.Errorf("query %q specifies parameter %q, which is not supported for MySQL", name, cmd)
+	}
 	q.Name = name
 	q.Cmd = cmd
 	return nil
@@ -479,13 +482,13 @@ func parseSelectAliasExpr(exprs sqlparser.SelectExprs, s *Schema, tableAliasMap
 }
 
 // GeneratePkg is the main entry to mysql generator package
-func GeneratePkg(pkgName, schemaPath, querysPath string, settings dinosql.GenerateSettings) (*Result, error) {
+func GeneratePkg(pkgName, schemaPath string, querysPaths []string, settings dinosql.GenerateSettings) (*Result, error) {
 	s := NewSchema()
-	_, err := parsePath(schemaPath, pkgName, s, settings)
+	_, err := parsePath([]string{schemaPath}, pkgName, s, settings)
 	if err != nil {
 		return nil, err
 	}
-	result, err := parsePath(querysPath, pkgName, s, settings)
+	result, err := parsePath(querysPaths, pkgName, s, settings)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +41,7 @@ func TestParseConfig(t *testing.T) {
 }
 
 func TestGeneratePkg(t *testing.T) {
-	_, err := GeneratePkg(mockSettings.Packages[0].Name, mockFileName, mockFileName, mockSettings)
+	_, err := GeneratePkg(mockSettings.Packages[0].Name, mockFileName, []string{mockFileName}, mockSettings)
 	if err != nil {
 		if pErr, ok := err.(*dinosql.ParserErr); ok {
 			for _, fileErr := range pErr.Errs {
@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"fmt"
+	"sort"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 )
@@ -19,6 +20,16 @@ type Schema struct {
 	tables map[string]([]*sqlparser.ColumnDefinition)
 }
 
+// tableNames returns the names of the schema's tables in sorted order
+func (s *Schema) tableNames() []string {
+	names := make([]string, 0, len(s.tables))
+	for name := range s.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // returns a deep copy of the column definition for using as a query return type or param type
 func (s *Schema) getColType(col *sqlparser.ColName, tableAliasMap FromTables, defaultTableName string) (*sqlparser.ColumnDefinition, error) {
 	realTable, err := tableColReferences(col, defaultTableName, tableAliasMap)
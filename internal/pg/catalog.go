@@ -20,9 +20,10 @@ func NewCatalog() Catalog {
 
 func NewSchema() Schema {
 	return Schema{
-		Tables: map[string]Table{},
-		Enums:  map[string]Enum{},
-		Funcs:  map[string][]Function{},
+		Tables:         map[string]Table{},
+		Enums:          map[string]Enum{},
+		CompositeTypes: map[string]CompositeType{},
+		Funcs:          map[string][]Function{},
 	}
 }
 
@@ -45,6 +46,10 @@ func (f FQN) String() string {
 
 type Catalog struct {
 	Schemas map[string]Schema
+
+	// Schemas searched, in order, when resolving an unqualified table name.
+	// Defaults to public.
+	SearchPath []string
 }
 
 func (c Catalog) LookupFunctions(fqn FQN) ([]Function, error) {
@@ -91,11 +96,12 @@ func (c Catalog) LookupFunctionN(fqn FQN, argn int) (Function, error) {
 }
 
 type Schema struct {
-	Name    string
-	Tables  map[string]Table
-	Enums   map[string]Enum
-	Funcs   map[string][]Function
-	Comment string
+	Name           string
+	Tables         map[string]Table
+	Enums          map[string]Enum
+	CompositeTypes map[string]CompositeType
+	Funcs          map[string][]Function
+	Comment        string
 }
 
 type Table struct {
@@ -112,17 +118,43 @@ type Column struct {
 	IsArray  bool
 	Comment  string
 
+	// True if the column is part of its table's primary key
+	PrimaryKey bool
+
+	// True if the column has a DEFAULT value or is an identity column
+	HasDefault bool
+
+	// The column referenced by the column's foreign key, if it has one
+	ForeignKey *ForeignKey
+
+	// The values allowed by a CHECK (column IN (...)) constraint
+	CheckValues []string
+
 	// XXX: Figure out what PostgreSQL calls `foo.id`
 	Scope string
 	Table FQN
 }
 
+// ForeignKey is the target of a REFERENCES constraint. Column is empty when
+// the constraint only names the table, which references its primary key.
+type ForeignKey struct {
+	Table  FQN
+	Column string
+}
+
 type Enum struct {
 	Name    string
 	Vals    []string
 	Comment string
 }
 
+// CompositeType is a type created with CREATE TYPE name AS (...)
+type CompositeType struct {
+	Name    string
+	Columns []Column
+	Comment string
+}
+
 type Function struct {
 	Name       string
 	ArgN       int
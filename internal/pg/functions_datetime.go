@@ -0,0 +1,103 @@
+package pg
+
+// Date/Time Functions and Operators
+//
+// https://www.postgresql.org/docs/current/functions-datetime.html
+//
+// Table 9.30. Date/Time Functions
+func dateTimeFunctions() []Function {
+	return []Function{
+		{
+			Name:       "age",
+			Desc:       "Subtract from current_date (at midnight)",
+			ReturnType: "pg_catalog.interval",
+			Arguments: []Argument{
+				{
+					DataType: "pg_catalog.timestamptz",
+				},
+			},
+		},
+		{
+			Name:       "age",
+			Desc:       "Subtract arguments, producing a symbolic result that uses years and months",
+			ReturnType: "pg_catalog.interval",
+			Arguments: []Argument{
+				{
+					DataType: "pg_catalog.timestamptz",
+				},
+				{
+					DataType: "pg_catalog.timestamptz",
+				},
+			},
+		},
+		{
+			Name:       "date_trunc",
+			Desc:       "Truncate to specified precision",
+			ReturnType: "pg_catalog.timestamptz",
+			Arguments: []Argument{
+				{
+					Name:     "field",
+					DataType: "text",
+				},
+				{
+					Name:     "source",
+					DataType: "pg_catalog.timestamptz",
+				},
+			},
+		},
+		{
+			Name:       "date_trunc",
+			Desc:       "Truncate to specified precision in the specified time zone",
+			ReturnType: "pg_catalog.timestamptz",
+			Arguments: []Argument{
+				{
+					Name:     "field",
+					DataType: "text",
+				},
+				{
+					Name:     "source",
+					DataType: "pg_catalog.timestamptz",
+				},
+				{
+					Name:     "time_zone",
+					DataType: "text",
+				},
+			},
+		},
+		{
+			Name:       "make_date",
+			Desc:       "Create date from year, month and day fields",
+			ReturnType: "date",
+			Arguments: []Argument{
+				{
+					Name:     "year",
+					DataType: "integer",
+				},
+				{
+					Name:     "month",
+					DataType: "integer",
+				},
+				{
+					Name:     "day",
+					DataType: "integer",
+				},
+			},
+		},
+		{
+			Name:       "now",
+			Desc:       "Current date and time (start of current transaction)",
+			ReturnType: "pg_catalog.timestamptz",
+			Arguments:  []Argument{},
+		},
+		{
+			Name:       "to_timestamp",
+			Desc:       "Convert Unix epoch (seconds since 1970-01-01 00:00:00+00) to timestamp",
+			ReturnType: "pg_catalog.timestamptz",
+			Arguments: []Argument{
+				{
+					DataType: "double precision",
+				},
+			},
+		},
+	}
+}
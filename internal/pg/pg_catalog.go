@@ -71,10 +71,44 @@ func pgCatalog() Schema {
 			ArgN:       1,
 			ReturnType: "bool",
 		},
+
+		// Table 9.60. General-Purpose Window Functions
+		// https://www.postgresql.org/docs/current/functions-window.html#FUNCTIONS-WINDOW-TABLE
+		{
+			Name:       "row_number",
+			ArgN:       0,
+			ReturnType: "bigint",
+		},
+		{
+			Name:       "rank",
+			ArgN:       0,
+			ReturnType: "bigint",
+		},
+		{
+			Name:       "dense_rank",
+			ArgN:       0,
+			ReturnType: "bigint",
+		},
+		{
+			Name:       "percent_rank",
+			ArgN:       0,
+			ReturnType: "double precision",
+		},
+		{
+			Name:       "cume_dist",
+			ArgN:       0,
+			ReturnType: "double precision",
+		},
+		{
+			Name:       "ntile",
+			ArgN:       1,
+			ReturnType: "integer",
+		},
 	}
 
 	fs = append(fs, stringFunctions()...)
 	fs = append(fs, advisoryLockFunctions()...)
+	fs = append(fs, dateTimeFunctions()...)
 
 	s.Funcs = make(map[string][]Function, len(fs))
 	for _, f := range fs {
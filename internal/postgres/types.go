@@ -17,6 +17,21 @@ func IsComparisonOperator(s string) bool {
 	return true
 }
 
+// IsContainmentOperator reports whether s is one of the jsonb or array
+// containment and existence operators, all of which return a boolean.
+func IsContainmentOperator(s string) bool {
+	switch s {
+	case "@>":
+	case "<@":
+	case "?":
+	case "?|":
+	case "?&":
+	default:
+		return false
+	}
+	return true
+}
+
 func IsMathematicalOperator(s string) bool {
 	switch s {
 	case "+":